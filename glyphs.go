@@ -1,113 +0,0 @@
-package main
-
-// Nothing-inspired dot-matrix glyphs (7x8 size) - compact vertical spacing
-var glyphs = map[rune][]string{
-	'0': {
-		"   ⬤⬤⬤  ",
-		"  ⬤   ⬤ ",
-		" ⬤     ⬤",
-		" ⬤     ⬤",
-		" ⬤     ⬤",
-		"  ⬤   ⬤ ",
-		"   ⬤⬤⬤  ",
-	},
-	'1': {
-		"    ⬤   ",
-		"   ⬤⬤   ",
-		"    ⬤   ",
-		"    ⬤   ",
-		"    ⬤   ",
-		"    ⬤   ",
-		"  ⬤⬤⬤⬤⬤ ",
-	},
-	'2': {
-		"   ⬤⬤⬤  ",
-		"  ⬤   ⬤ ",
-		"      ⬤ ",
-		"     ⬤  ",
-		"    ⬤   ",
-		"   ⬤    ",
-		"  ⬤⬤⬤⬤⬤ ",
-	},
-	'3': {
-		"   ⬤⬤⬤  ",
-		"  ⬤   ⬤ ",
-		"      ⬤ ",
-		"    ⬤⬤  ",
-		"      ⬤ ",
-		"  ⬤   ⬤ ",
-		"   ⬤⬤⬤  ",
-	},
-	'4': {
-		"  ⬤    ⬤",
-		"  ⬤    ⬤",
-		"  ⬤    ⬤",
-		"  ⬤⬤⬤⬤⬤⬤",
-		"       ⬤",
-		"       ⬤",
-		"       ⬤",
-	},
-	'5': {
-		"  ⬤⬤⬤⬤⬤ ",
-		"  ⬤     ",
-		"  ⬤     ",
-		"  ⬤⬤⬤⬤  ",
-		"       ⬤",
-		"  ⬤   ⬤ ",
-		"   ⬤⬤⬤  ",
-	},
-	'6': {
-		"   ⬤⬤⬤⬤ ",
-		"  ⬤     ",
-		"  ⬤     ",
-		"  ⬤⬤⬤⬤  ",
-		"  ⬤   ⬤ ",
-		"  ⬤   ⬤ ",
-		"   ⬤⬤⬤  ",
-	},
-	'7': {
-		"  ⬤⬤⬤⬤⬤ ",
-		"       ⬤",
-		"      ⬤ ",
-		"     ⬤  ",
-		"    ⬤   ",
-		"   ⬤    ",
-		"  ⬤     ",
-	},
-	'8': {
-		"   ⬤⬤⬤  ",
-		"  ⬤   ⬤ ",
-		"  ⬤   ⬤ ",
-		"   ⬤⬤⬤  ",
-		"  ⬤   ⬤ ",
-		"  ⬤   ⬤ ",
-		"   ⬤⬤⬤  ",
-	},
-	'9': {
-		"   ⬤⬤⬤  ",
-		"  ⬤   ⬤ ",
-		"  ⬤   ⬤ ",
-		"   ⬤⬤⬤⬤ ",
-		"      ⬤ ",
-		"  ⬤  ⬤  ",
-		"   ⬤⬤   ",
-	},
-	':': {
-		"        ",
-		"   ⬤⬤   ",
-		"   ⬤⬤   ",
-		"        ",
-		"   ⬤⬤   ",
-		"   ⬤⬤   ",
-		"        ",
-	},
-	' ': {
-		"        ",
-		"        ",
-		"        ",
-		"        ",
-		"        ",
-		"        ",
-		"        ",
-	},
-}
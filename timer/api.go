@@ -0,0 +1,152 @@
+// Package timer is go-timer: Timer, a pausable countdown or stopwatch clock
+// with no terminal handling for use from other Go programs, and Main, the
+// terminal app built on the same elapsed-time rules.
+package timer
+
+import (
+	"context"
+	"sync"
+	"time"
+)
+
+// Timer counts down from a duration, or up when the duration is 0.
+// All methods are safe for concurrent use.
+type Timer struct {
+	mu          sync.Mutex
+	duration    time.Duration
+	start       time.Time
+	pausedTotal time.Duration
+	paused      bool
+	pauseStart  time.Time
+	offset      time.Duration // Added to a stopwatch before Start
+
+	// Now returns the current time; replace it before Start to control the clock
+	Now func() time.Time
+}
+
+// New returns a stopped timer for duration (0 means stopwatch). Call Start or
+// Run to begin counting.
+func New(duration time.Duration) *Timer {
+	return &Timer{duration: duration, paused: true, Now: time.Now}
+}
+
+// Start begins counting, with elapsed already counted (e.g. a restored session)
+func (t *Timer) Start(elapsed time.Duration) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	current := t.Now()
+	t.start = current.Add(-elapsed - t.offset)
+	t.offset = 0
+	t.pausedTotal = 0
+	t.paused = false
+}
+
+// Pause stops the clock; it has no effect when already paused
+func (t *Timer) Pause() {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	if !t.paused {
+		t.paused = true
+		t.pauseStart = t.Now()
+	}
+}
+
+// Resume continues counting after Pause
+func (t *Timer) Resume() {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	if t.paused && !t.start.IsZero() {
+		t.pausedTotal += t.Now().Sub(t.pauseStart)
+		t.paused = false
+	}
+}
+
+// Paused reports whether the clock is stopped
+func (t *Timer) Paused() bool {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	return t.paused
+}
+
+// Add extends a countdown (or shifts a stopwatch forward) by d; negative values
+// shorten it, but never below the time already elapsed
+func (t *Timer) Add(d time.Duration) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	if t.duration == 0 {
+		if t.start.IsZero() {
+			t.offset = max(t.offset+d, 0)
+			return
+		}
+		t.start = t.start.Add(-d)
+		if elapsed := t.elapsed(); elapsed < 0 {
+			t.start = t.start.Add(elapsed)
+		}
+		return
+	}
+	t.duration += d
+	if elapsed := t.elapsed(); t.duration < elapsed {
+		t.duration = elapsed
+	}
+}
+
+// Elapsed returns the counted time, excluding pauses
+func (t *Timer) Elapsed() time.Duration {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	return t.elapsed()
+}
+
+// Remaining returns the time left on a countdown (0 for a stopwatch or once finished)
+func (t *Timer) Remaining() time.Duration {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	if t.duration == 0 {
+		return 0
+	}
+	if remaining := t.duration - t.elapsed(); remaining > 0 {
+		return remaining
+	}
+	return 0
+}
+
+// Finished reports whether a countdown has run out; a stopwatch never finishes
+func (t *Timer) Finished() bool {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	return t.duration > 0 && t.elapsed() >= t.duration
+}
+
+func (t *Timer) elapsed() time.Duration {
+	if t.start.IsZero() {
+		return t.offset
+	}
+	return elapsedAt(t.Now(), t.start, t.pausedTotal, t.paused, t.pauseStart)
+}
+
+// Run starts the timer if needed and calls onTick every interval until the
+// countdown finishes (returning nil) or ctx is cancelled (returning its error).
+func (t *Timer) Run(ctx context.Context, interval time.Duration, onTick func(*Timer)) error {
+	t.mu.Lock()
+	notStarted := t.start.IsZero()
+	t.mu.Unlock()
+	if notStarted {
+		t.Start(0)
+	}
+
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-ticker.C:
+			if onTick != nil {
+				onTick(t)
+			}
+			if t.Finished() {
+				return nil
+			}
+		}
+	}
+}
@@ -0,0 +1,85 @@
+package timer
+
+import (
+	"context"
+	"testing"
+	"time"
+)
+
+// fakeClock returns a Now func and a function that advances it
+func fakeClock() (func() time.Time, func(time.Duration)) {
+	current := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	return func() time.Time { return current }, func(d time.Duration) { current = current.Add(d) }
+}
+
+func TestCountdown(t *testing.T) {
+	tm := New(time.Minute)
+	var advance func(time.Duration)
+	tm.Now, advance = fakeClock()
+
+	tm.Start(0)
+	advance(20 * time.Second)
+	if got := tm.Remaining(); got != 40*time.Second {
+		t.Fatalf("expected 40s remaining, got %v", got)
+	}
+
+	tm.Pause()
+	advance(time.Hour)
+	if got := tm.Elapsed(); got != 20*time.Second {
+		t.Fatalf("paused time should not count, got %v", got)
+	}
+	tm.Resume()
+
+	tm.Add(-time.Hour)
+	if !tm.Finished() || tm.Remaining() != 0 {
+		t.Fatalf("shortening below elapsed should finish the countdown")
+	}
+}
+
+func TestStopwatch(t *testing.T) {
+	tm := New(0)
+	var advance func(time.Duration)
+	tm.Now, advance = fakeClock()
+
+	tm.Start(5 * time.Second)
+	advance(10 * time.Second)
+	tm.Add(time.Minute)
+	if got := tm.Elapsed(); got != 75*time.Second {
+		t.Fatalf("expected 75s elapsed, got %v", got)
+	}
+	if tm.Finished() || tm.Remaining() != 0 {
+		t.Fatalf("a stopwatch never finishes")
+	}
+
+	// Time added before Start is carried into the count
+	tm = New(0)
+	tm.Now, advance = fakeClock()
+	tm.Add(time.Minute)
+	tm.Add(-2 * time.Minute)
+	tm.Add(30 * time.Second)
+	if got := tm.Elapsed(); got != 30*time.Second {
+		t.Fatalf("expected 30s pending before Start, got %v", got)
+	}
+	tm.Start(5 * time.Second)
+	advance(time.Second)
+	if got := tm.Elapsed(); got != 36*time.Second {
+		t.Fatalf("expected 36s elapsed, got %v", got)
+	}
+}
+
+func TestRun(t *testing.T) {
+	tm := New(30 * time.Millisecond)
+	ticks := 0
+	if err := tm.Run(context.Background(), 5*time.Millisecond, func(*Timer) { ticks++ }); err != nil {
+		t.Fatalf("Run: %v", err)
+	}
+	if ticks == 0 || !tm.Finished() {
+		t.Fatalf("expected ticks until finished, got %d ticks", ticks)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	if err := New(0).Run(ctx, time.Millisecond, nil); err != context.Canceled {
+		t.Fatalf("expected context.Canceled, got %v", err)
+	}
+}
@@ -0,0 +1,730 @@
+package timer
+
+import (
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+	"time"
+)
+
+// cliFlags holds the command-line flags; a FlagSet of its own keeps them out of
+// flag.CommandLine for programs that embed the package
+var cliFlags = flag.NewFlagSet("timer", flag.ExitOnError)
+
+var (
+	inlineMode     = cliFlags.Bool("inline", false, "run in inline mode (disable fullscreen TUI)")
+	inlineModeS    = cliFlags.Bool("i", false, "run in inline mode (shorthand for -inline)")
+	showVersion    = cliFlags.Bool("version", false, "display version information")
+	showVersionS   = cliFlags.Bool("v", false, "display version information (shorthand for -version)")
+	pausedMode     = cliFlags.Bool("paused", false, "start timer in paused state")
+	pausedModeS    = cliFlags.Bool("p", false, "start timer in paused state (shorthand for -paused)")
+	startPaused    = cliFlags.Bool("start-paused", false, "wait for a keypress before the timer starts counting")
+	promptStart    = cliFlags.Bool("prompt-start", false, "like -start-paused, but show instructions (startPrompt) instead of the clock until a key is pressed")
+	timerName      = cliFlags.String("session", "", "name for the timer")
+	restoreMode    = cliFlags.Bool("restore", false, "restore timer from sessions.json")
+	restoreModeS   = cliFlags.Bool("r", false, "restore timer from sessions.json (shorthand)")
+	counterGoal    = cliFlags.String("goal", "", "goal for counter mode; shows +overtime once exceeded")
+	continueName   = cliFlags.String("continue", "", "continue counting the named counter session from its stored total")
+	checkpointsArg = cliFlags.String("checkpoints", "", "comma-separated alert points, e.g. 10m,5m,1m (remaining time; elapsed for counters)")
+	waitFor        = cliFlags.String("wait-for", "", "block until the named session finishes, then exit (non-zero if it is deleted or times out)")
+	waitTimeout    = cliFlags.String("timeout", "", "with -wait-for: give up after this long")
+	editSessions   = cliFlags.Bool("sessions", false, "with edit: open sessions.json instead of the config")
+	profileName    = cliFlags.String("profile", "", "use config.<profile>.json (default $TIMER_PROFILE)")
+	noPersist      = cliFlags.Bool("no-persist", false, "don't read or write sessions.json for this run")
+	outputFormat   = cliFlags.String("format", "human", "final output: human, seconds, clock or json")
+	cornerMode     = cliFlags.Bool("corner", false, "show a compact countdown in the top-right corner, leaving the rest of the terminal usable")
+	accessible     = cliFlags.Bool("accessible", false, "announce the time as plain lines of words for screen readers instead of drawing glyphs")
+	description    = cliFlags.String("desc", "", "description shown below the digits in fullscreen and saved with the session")
+	groupName      = cliFlags.String("group", "", "group the session belongs to; with watch: the group to show")
+	noMouse        = cliFlags.Bool("no-mouse", false, "never turn on mouse reporting, so the mouse selects text as usual")
+	noClear        = cliFlags.Bool("no-clear", false, "leave the final frame in the terminal on exit (draws on the main screen instead of the alternate one)")
+	influxDest     = cliFlags.String("influx", "", "instead of drawing, write an InfluxDB line-protocol point each second to - (stdout) or a UDP host:port")
+	tuiStderr      = cliFlags.Bool("tui-stderr", false, "draw the live display on stderr, keeping stdout for the summary")
+)
+
+// listFlag collects a flag that may be given several times, each value
+// possibly a comma-separated list
+type listFlag []string
+
+func (l *listFlag) String() string { return strings.Join(*l, ",") }
+
+func (l *listFlag) Set(value string) error {
+	*l = append(*l, strings.Split(value, ",")...)
+	return nil
+}
+
+// pauseAtArg holds the -pause-at points
+var pauseAtArg listFlag
+
+func init() {
+	cliFlags.Var(&pauseAtArg, "pause-at", "stop at this remaining time (elapsed for counters) until a key is pressed; repeatable or comma-separated, e.g. -pause-at 2m -pause-at 30s")
+}
+
+func usage() {
+	fmt.Fprintf(os.Stderr, "timer - minimal tui countdown/timer app under 5mb memory usage \n\n")
+	fmt.Fprintf(os.Stderr, "Usage: timer [options] [<duration>]\n")
+	fmt.Fprintf(os.Stderr, "       timer [-session NAME] +<duration>|-<duration>\n")
+	fmt.Fprintf(os.Stderr, "       timer reset [NAME]\n")
+	fmt.Fprintf(os.Stderr, "       timer create NAME=DURATION...\n")
+	fmt.Fprintf(os.Stderr, "       timer edit [-sessions]\n")
+	fmt.Fprintf(os.Stderr, "       timer split NAME1 NAME2\n")
+	fmt.Fprintf(os.Stderr, "       timer watch -group NAME\n")
+	fmt.Fprintf(os.Stderr, "       timer prompt [NAME]\n")
+	fmt.Fprintf(os.Stderr, "       timer parse [-format json] DURATION|+DURATION|-DURATION\n")
+	fmt.Fprintf(os.Stderr, "       timer -wait-for NAME [-timeout DURATION]\n")
+	fmt.Fprintf(os.Stderr, "       timer version\n\n")
+	fmt.Fprintf(os.Stderr, "Duration: number with unit (5s, 2m, 1h). No unit defaults to seconds.\n")
+	fmt.Fprintf(os.Stderr, "          If omitted, runs as a counter (stopwatch) counting up from 00:00\n")
+	fmt.Fprintf(os.Stderr, "          (see noArgBehavior in config.json).\n\n")
+	fmt.Fprintf(os.Stderr, "Options:\n")
+	cliFlags.PrintDefaults()
+	fmt.Fprintf(os.Stderr, "\nExamples:\n")
+	fmt.Fprintf(os.Stderr, "  timer                    # counter/stopwatch (counts up)\n")
+	fmt.Fprintf(os.Stderr, "  timer 5                  # 5 seconds countdown (fullscreen)\n")
+	fmt.Fprintf(os.Stderr, "  timer 2m                 # 2 minutes countdown (fullscreen)\n")
+	fmt.Fprintf(os.Stderr, "  timer -i 30s             # inline mode countdown\n")
+	fmt.Fprintf(os.Stderr, "  timer -p 5m              # 5 minutes countdown starting paused\n")
+	fmt.Fprintf(os.Stderr, "  timer -start-paused 5m   # start counting on the first keypress\n")
+	fmt.Fprintf(os.Stderr, "  timer -prompt-start 5m   # \"Press any key to start\" screen for classrooms\n")
+	fmt.Fprintf(os.Stderr, "  timer -corner 10m        # small overlay for live demos\n")
+	fmt.Fprintf(os.Stderr, "  timer -accessible 10m    # spoken-style updates for screen readers\n")
+	fmt.Fprintf(os.Stderr, "  timer -goal 1h           # counter that shows +overtime after 1 hour\n")
+	fmt.Fprintf(os.Stderr, "  timer +5m                # add 5 minutes to the default session\n")
+	fmt.Fprintf(os.Stderr, "  timer -session tea -- -1m  # take a minute off \"tea\"\n")
+	fmt.Fprintf(os.Stderr, "  timer -continue work     # keep adding to the \"work\" counter's total\n")
+	fmt.Fprintf(os.Stderr, "  timer -no-persist 3m     # throwaway timer, sessions.json untouched\n")
+	fmt.Fprintf(os.Stderr, "  RESULT=$(timer -tui-stderr -format seconds)  # capture elapsed seconds\n")
+	fmt.Fprintf(os.Stderr, "  timer -session Pomodoro 25m    # named timer with notification\n")
+	fmt.Fprintf(os.Stderr, "  timer --restore                # restore \"default\" session from sessions.json\n")
+	fmt.Fprintf(os.Stderr, "  timer --restore --session NAME    # restore session named NAME\n")
+	fmt.Fprintf(os.Stderr, "  timer --restore -i             # restore in inline mode regardless of saved setting\n")
+}
+
+// isBoolFlag reports whether a flag takes no value
+func isBoolFlag(f *flag.Flag) bool {
+	b, ok := f.Value.(interface{ IsBoolFlag() bool })
+	return ok && b.IsBoolFlag()
+}
+
+// resolveNoArg decides what a bare `timer` runs according to noArgBehavior:
+// a counter (0), defaultDuration, or the usage text (ok is false)
+func resolveNoArg() (duration time.Duration, ok bool) {
+	switch noArgBehavior {
+	case "usage":
+		return 0, false
+	case "default":
+		if defaultDuration > 0 {
+			return defaultDuration, true
+		}
+		warnf("noArgBehavior is \"default\" but no defaultDuration is set; counting up")
+	}
+	return 0, true
+}
+
+// finishedRestoreAction decides what restoring session does when it has
+// already finished, following restoreFinished: restart runs it again from the
+// beginning, a non-empty skip is printed instead of running it, and neither
+// shows it as it was left
+func finishedRestoreAction(session Session) (restart bool, skip string) {
+	if !session.Finished {
+		return false, ""
+	}
+	switch restoreFinished {
+	case "ignore":
+		label := session.Name
+		if label == "" {
+			label = "default"
+		}
+		return false, fmt.Sprintf("timer: session %q has already finished; nothing to restore", label)
+	case "restart":
+		return true, ""
+	}
+	return false, ""
+}
+
+// parseTrailingArgs sorts the arguments left after the first flag parse, e.g.
+// the ones after a subcommand: flags are applied to cliFlags, a "+5m"/"-2m"
+// fills an empty relativeArg, and the rest are returned as positional
+func parseTrailingArgs(args []string, relativeArg string) (positional []string, relative string, err error) {
+	for i := 0; i < len(args); i++ {
+		arg := args[i]
+		if (isNegativeDuration(arg) || strings.HasPrefix(arg, "+")) && relativeArg == "" {
+			relativeArg = arg
+		} else if strings.HasPrefix(arg, "-") {
+			if arg == "-i" || arg == "-inline" {
+				*inlineMode = true
+			} else if arg == "-p" || arg == "-paused" {
+				*pausedMode = true
+			} else if arg == "-v" || arg == "-version" {
+				*showVersion = true
+			} else if arg == "-sessions" || arg == "--sessions" {
+				*editSessions = true
+			} else if name, value, hasValue := strings.Cut(strings.TrimLeft(arg, "-"), "="); cliFlags.Lookup(name) != nil && !isBoolFlag(cliFlags.Lookup(name)) {
+				// A flag with a value after a subcommand, e.g. watch -group work
+				if !hasValue {
+					if i+1 == len(args) {
+						return nil, "", errShowUsage
+					}
+					i++
+					value = args[i]
+				}
+				if err := cliFlags.Set(name, value); err != nil {
+					return nil, "", badInputf("invalid value %q for -%s: %v", value, name, err)
+				}
+			} else {
+				return nil, "", errShowUsage
+			}
+		} else {
+			positional = append(positional, arg)
+		}
+	}
+	return positional, relativeArg, nil
+}
+
+// Main runs the timer command line on os.Args and exits on errors
+func Main() {
+	cliFlags.Usage = usage
+
+	// "-2m" (trim the stored session) would otherwise be rejected as an unknown flag
+	var relativeArg string
+	var cliArgs []string
+	for i, arg := range os.Args[1:] {
+		if arg == "--" {
+			cliArgs = append(cliArgs, os.Args[i+1:]...)
+			break
+		}
+		if prev := os.Args[i]; i > 0 && strings.HasPrefix(prev, "-") && !strings.Contains(prev, "=") {
+			if f := cliFlags.Lookup(strings.TrimLeft(prev, "-")); f != nil && !isBoolFlag(f) {
+				cliArgs = append(cliArgs, arg)
+				continue // Value of a string flag, e.g. -session -5s
+			}
+		}
+		if isNegativeDuration(arg) && relativeArg == "" {
+			relativeArg = arg
+			continue
+		}
+		cliArgs = append(cliArgs, arg)
+	}
+	cliFlags.Parse(cliArgs)
+
+	// Load configuration from ~/.config/go-timer/config.json (or a profile)
+	if *profileName != "" {
+		setConfigProfile(*profileName)
+	} else if env := os.Getenv("TIMER_PROFILE"); env != "" {
+		setConfigProfile(env)
+	}
+	loadConfig()
+	applyGlyphStyle()
+
+	// Get args after initial flag parse
+	args := cliFlags.Args()
+
+	// Separate flags and positional from remaining args
+	positional, relativeArg, err := parseTrailingArgs(args, relativeArg)
+	if errors.Is(err, errShowUsage) {
+		usage()
+		os.Exit(exitUsage)
+	} else if err != nil {
+		fail(err)
+	}
+
+	// Handle version (flag or "timer version" subcommand)
+	if *showVersion || *showVersionS || (len(positional) == 1 && positional[0] == "version") {
+		fmt.Println(versionString())
+		return
+	}
+
+	// Reject an unknown output format before starting the TUI
+	if _, err := formatSummary(TimerSummary{}, *outputFormat); err != nil {
+		fail(badInput(err))
+	}
+
+	// "--wait-for NAME" blocks until another terminal's session finishes
+	if *waitFor != "" {
+		if len(positional) > 0 {
+			usage()
+			os.Exit(exitUsage)
+		}
+		name, err := normalizeSessionName(*waitFor)
+		if err != nil {
+			fail(badInput(err))
+		}
+		var timeout time.Duration
+		if *waitTimeout != "" {
+			if timeout, err = parseDurationArg(*waitTimeout); err != nil {
+				fail(badInputf("invalid -timeout: %v", err))
+			}
+		}
+		if err := waitForSession(name, timeout); err != nil {
+			fail(err)
+		}
+		return
+	}
+
+	// "timer split NAME1 NAME2" shows two sessions side by side
+	if len(positional) >= 1 && positional[0] == "split" {
+		if len(positional) != 3 {
+			usage()
+			os.Exit(exitUsage)
+		}
+		var names [2]string
+		for i, arg := range positional[1:] {
+			name, err := normalizeSessionName(arg)
+			if err != nil {
+				fail(badInput(err))
+			}
+			if name == "" {
+				name = "default"
+			}
+			names[i] = name
+		}
+		if *tuiStderr {
+			tuiOutput = "stderr"
+		}
+		setTUIOutput(tuiOutput)
+		if err := runSplit(names); err != nil {
+			fail(err)
+		}
+		return
+	}
+
+	// "timer watch -group NAME" shows a group's members and their total
+	if len(positional) >= 1 && positional[0] == "watch" {
+		if len(positional) != 1 {
+			usage()
+			os.Exit(exitUsage)
+		}
+		group, err := normalizeSessionName(*groupName)
+		if err != nil {
+			fail(badInputf("invalid -group: %v", err))
+		}
+		if group == "" {
+			fail(badInputf("watch needs -group NAME"))
+		}
+		if *tuiStderr {
+			tuiOutput = "stderr"
+		}
+		setTUIOutput(tuiOutput)
+		if err := runWatch(group); err != nil {
+			fail(err)
+		}
+		return
+	}
+
+	// "timer edit [--sessions]" opens the config or sessions.json in $EDITOR
+	if len(positional) == 1 && positional[0] == "edit" {
+		if err := runEdit(*editSessions); err != nil {
+			fail(err)
+		}
+		return
+	}
+
+	// "timer create NAME=DURATION..." writes paused countdowns without running them
+	if len(positional) >= 1 && positional[0] == "create" {
+		if len(positional) < 2 {
+			usage()
+			os.Exit(exitUsage)
+		}
+		sessions, err := parseCreateSpecs(positional[1:])
+		if err != nil {
+			fail(badInput(err))
+		}
+		if err := createSessions(sessions); err != nil {
+			fail(err)
+		}
+		for _, session := range sessions {
+			fmt.Printf("Created %s (%s, paused)\n", session.Name, formatHMS(parseFormattedDuration(session.Remaining)))
+		}
+		return
+	}
+
+	// "timer prompt [NAME]" prints the running timer for a shell prompt
+	if len(positional) >= 1 && positional[0] == "prompt" {
+		if len(positional) > 2 {
+			usage()
+			os.Exit(exitUsage)
+		}
+		name := *timerName
+		if len(positional) == 2 {
+			name = positional[1]
+		}
+		name, err := normalizeSessionName(name)
+		if err != nil {
+			fail(badInput(err))
+		}
+		runPrompt(name)
+		return
+	}
+
+	// "timer parse DURATION" validates a duration the way the timer reads it
+	if len(positional) >= 1 && positional[0] == "parse" {
+		var d time.Duration
+		var err error
+		switch {
+		case relativeArg != "" && len(positional) == 1:
+			d, err = parseRelativeArg(relativeArg)
+		case relativeArg == "" && len(positional) == 2:
+			d, err = parseDurationArg(positional[1])
+		default:
+			usage()
+			os.Exit(exitUsage)
+		}
+		if err != nil {
+			fail(badInput(err))
+		}
+		fmt.Println(formatParsed(d, *outputFormat))
+		return
+	}
+
+	// "timer reset [NAME]" zeroes a counter session without deleting it
+	if len(positional) >= 1 && positional[0] == "reset" {
+		if len(positional) > 2 {
+			usage()
+			os.Exit(exitUsage)
+		}
+		name := *timerName
+		if len(positional) == 2 {
+			name = positional[1]
+		}
+		session, err := resetSession(name)
+		if err != nil {
+			fail(err)
+		}
+		label := session.Name
+		if label == "" {
+			label = "default"
+		}
+		fmt.Printf("Reset %s to 00:00\n", label)
+		return
+	}
+
+	// Accept 0 or 1 positional arg
+	if len(positional) > 1 {
+		usage()
+		os.Exit(exitUsage)
+	}
+
+	// Parse duration (0 means counter mode)
+	var duration time.Duration
+	if len(positional) == 0 {
+		// Counter mode - use 0 duration as signal
+		duration = 0
+	} else {
+		var err error
+		duration, err = parseDurationArg(positional[0])
+		if err != nil {
+			fail(badInput(err))
+		}
+	}
+
+	// Validate session name
+	name, err := normalizeSessionName(*timerName)
+	if err != nil {
+		fail(badInput(err))
+	}
+	*timerName = name
+	if *groupName, err = normalizeSessionName(*groupName); err != nil {
+		fail(badInputf("invalid -group: %v", err))
+	}
+
+	// "+5m" / "-2m" adjust the stored session without opening the TUI
+	if relativeArg != "" {
+		if len(positional) > 0 {
+			usage()
+			os.Exit(exitUsage)
+		}
+		delta, err := parseRelativeArg(relativeArg)
+		if err != nil {
+			fail(badInput(err))
+		}
+		session, err := adjustSession(*timerName, delta)
+		if err != nil {
+			fail(err)
+		}
+		label := session.Name
+		if label == "" {
+			label = "default"
+		}
+		fmt.Printf("Adjusted %s by %s (pending %s)\n", label, relativeArg, session.Adjust)
+		return
+	}
+
+	// Continue an existing counter session (always counter mode)
+	isContinue := *continueName != ""
+	if isContinue {
+		name, err := normalizeSessionName(*continueName)
+		if err != nil {
+			fail(badInput(err))
+		}
+		*timerName = name
+		if duration != 0 {
+			warnf("-continue resumes a counter; ignoring duration %q", positional[0])
+			duration = 0
+		}
+	}
+
+	// No duration and nothing to restore or continue: apply noArgBehavior
+	if len(positional) == 0 && !isContinue && *counterGoal == "" && !*restoreMode && !*restoreModeS && !restoreEnabled {
+		var ok bool
+		if duration, ok = resolveNoArg(); !ok {
+			usage()
+			os.Exit(exitUsage)
+		}
+	}
+
+	// Merge command-line checkpoints with the configured ones
+	if *checkpointsArg != "" {
+		var err error
+		if checkpoints, err = parseCheckpoints(checkpoints, strings.Split(*checkpointsArg, ",")); err != nil {
+			fail(badInput(err))
+		}
+	}
+
+	// Parse -pause-at points, sorted and without duplicates like checkpoints
+	var pauseAt []time.Duration
+	if len(pauseAtArg) > 0 {
+		var err error
+		if pauseAt, err = parseCheckpoints(nil, pauseAtArg); err != nil {
+			fail(badInputf("invalid -pause-at: %v", errors.Unwrap(err)))
+		}
+	}
+
+	// Parse counter goal
+	var goal time.Duration
+	if *counterGoal != "" {
+		var err error
+		goal, err = parseDurationArg(*counterGoal)
+		if err != nil {
+			fail(badInputf("invalid goal: %v", err))
+		}
+		if duration != 0 {
+			fail(badInputf("-goal only applies to counter mode"))
+		}
+	}
+
+	// Handle restore mode (manual or auto)
+	isRestore := *restoreMode || *restoreModeS
+	if isRestore && isContinue {
+		fail(badInputf("-continue and -restore cannot be combined"))
+	}
+	if *noPersist {
+		if isRestore || isContinue {
+			fail(badInputf("-no-persist cannot be combined with -restore or -continue"))
+		}
+		persistSessions = false
+	}
+	if duration == 0 && restoreEnabled && !isRestore && !isContinue && persistSessions {
+		// Auto-restore if no duration specified and config has restore=true
+		isRestore = true
+	}
+	var restoredSession Session
+	var initialElapsed time.Duration
+	var overrides *SessionOverrides
+	var showToGoal bool
+	night := nightMode
+	var pauses []PauseInterval
+	if isRestore || isContinue {
+		var err error
+		restoredSession, err = takeSession(*timerName) // Its queued change is applied below
+		if err != nil {
+			fail(err)
+		}
+		if isContinue && restoredSession.Mode != "counter" {
+			fail(badInputf("session %q is not a counter", *timerName))
+		}
+		finishedRestart := false
+		if isRestore {
+			var skip string
+			if finishedRestart, skip = finishedRestoreAction(restoredSession); skip != "" {
+				fmt.Fprintln(os.Stderr, skip)
+				return
+			}
+		}
+		if finishedRestart {
+			// Run it again from the beginning
+			restoredSession.Paused = false
+		}
+		// Override parameters from session
+		if restoredSession.Mode == "counter" {
+			duration = 0
+			if goal == 0 {
+				goal = parseFormattedDuration(restoredSession.Goal)
+			}
+			showToGoal = restoredSession.ShowToGoal
+			if isContinue {
+				// Keep counting from the stored total
+				initialElapsed = restoredElapsed(restoredSession, now())
+			}
+		} else {
+			elapsed := parseFormattedDuration(restoredSession.Elapsed)
+			remaining := parseFormattedDuration(restoredSession.Remaining)
+			duration = elapsed + remaining - parseFormattedDuration(restoredSession.Overtime)
+			initialElapsed = restoredElapsed(restoredSession, now())
+		}
+		if finishedRestart {
+			initialElapsed = 0
+		}
+		// Apply a "+5m"/"-2m" adjustment queued while the timer wasn't running
+		if adjust := parseFormattedDuration(restoredSession.Adjust); adjust != 0 {
+			if restoredSession.Mode == "counter" {
+				initialElapsed = max(initialElapsed+adjust, 0)
+			} else {
+				duration = max(duration+adjust, initialElapsed)
+			}
+		}
+		if *timerName == "" {
+			*timerName = restoredSession.Name
+		}
+		overrides = restoredSession.Overrides
+		night = restoredSession.Night
+		if !finishedRestart {
+			pauses = restoredSession.Pauses
+		}
+		// Points that already fired stay fired unless -pause-at is given again
+		if len(pauseAtArg) == 0 && !finishedRestart {
+			for _, point := range restoredSession.PauseAt {
+				pauseAt = append(pauseAt, parseFormattedDuration(point))
+			}
+		}
+		if *description == "" {
+			*description = restoredSession.Description
+		}
+		if *groupName == "" {
+			*groupName = restoredSession.Group
+		}
+	} else if !persistSessions {
+		// Ephemeral run: global config only
+	} else if existing, err := takeSession(*timerName); err == nil {
+		// Keep overrides, the description and the group of an existing session
+		// with the same name; a change queued on it doesn't carry over
+		overrides = existing.Overrides
+		if *description == "" {
+			*description = existing.Description
+		}
+		if *groupName == "" {
+			*groupName = existing.Group
+		}
+	}
+
+	// Catch a missing notifier or sound player now rather than when the timer finishes
+	checkSounds()
+	checkSpeech()
+	if duration > 0 {
+		checkNotifier()
+	}
+
+	// Choose the display stream (flag overrides config)
+	if *tuiStderr {
+		tuiOutput = "stderr"
+	}
+	setTUIOutput(tuiOutput)
+
+	// The alternate screen would take the final frame with it
+	if *noClear {
+		useAltScreen = false
+	}
+	if *noMouse {
+		mouseEnabled = false
+	}
+
+	// Merge short/long flags - fullscreen is default, inline disables it
+	userProvidedInline := *inlineMode || *inlineModeS
+	userProvidedPaused := *pausedMode || *pausedModeS
+
+	useInline := userProvidedInline
+	initialPaused := userProvidedPaused
+
+	if isRestore {
+		if !userProvidedPaused {
+			initialPaused = restoredSession.Paused
+		}
+		if !userProvidedInline {
+			useInline = restoredSession.Inline
+		}
+	}
+	if isContinue && !userProvidedInline {
+		useInline = restoredSession.Inline
+	}
+
+	// Metrics replace the display entirely; keys still control the timer
+	var influx io.Writer
+	if *influxDest != "" {
+		if userProvidedInline || *cornerMode || *accessible || *tuiStderr {
+			fail(badInputf("-influx cannot be combined with -inline, -corner, -accessible or -tui-stderr"))
+		}
+		w, err := openInflux(*influxDest)
+		if err != nil {
+			fail(err)
+		}
+		defer w.Close()
+		influx = w
+		tuiOut = io.Discard
+	}
+
+	// Channel for timer summary
+	summaryCh := make(chan TimerSummary, 1)
+
+	// Run timer (fullscreen unless inline flag is set)
+	opts := timerOptions{
+		Duration:       duration,
+		Fullscreen:     !useInline && !*cornerMode && !*accessible && influx == nil,
+		Paused:         initialPaused,
+		Name:           *timerName,
+		InitialElapsed: initialElapsed,
+		Overrides:      overrides,
+		Goal:           goal,
+		WaitForStart:   *startPaused || *promptStart,
+		PromptStart:    *promptStart,
+		NoClear:        *noClear,
+		Description:    *description,
+		Group:          *groupName,
+		Pauses:         pauses,
+		PauseAt:        pauseAt,
+		Night:          night,
+		Corner:         *cornerMode && !*accessible,
+		Checkpoints:    checkpoints,
+		ShowToGoal:     showToGoal,
+		Accessible:     *accessible,
+		Influx:         influx,
+	}
+	for {
+		if err := runTimer(opts, summaryCh); err != nil {
+			fail(err)
+		}
+
+		// Receive and print summary
+		summary := <-summaryCh
+		// Points on stdout stay parseable without the summary
+		if *influxDest != "-" {
+			out, _ := formatSummary(summary, *outputFormat)
+			fmt.Print(out)
+		}
+		if !summary.Snoozed {
+			return
+		}
+
+		// Snoozed from the notification: run a fresh countdown under the same name
+		opts = snoozeOptions(opts)
+	}
+}
+
+// snoozeOptions returns the options for the snoozeDuration countdown that follows
+// a snoozed run: shown the same way, but with none of its time, pauses or points
+func snoozeOptions(opts timerOptions) timerOptions {
+	return timerOptions{
+		Duration:    snoozeDuration,
+		Fullscreen:  opts.Fullscreen,
+		Name:        opts.Name,
+		Overrides:   opts.Overrides,
+		NoClear:     opts.NoClear,
+		Description: opts.Description,
+		Night:       opts.Night,
+		Group:       opts.Group,
+		Corner:      opts.Corner,
+		Accessible:  opts.Accessible,
+		Influx:      opts.Influx,
+	}
+}
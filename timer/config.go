@@ -0,0 +1,799 @@
+package timer
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Build information, stamped at build time with (PKG=github.com/Zihad550/go-timer/timer):
+//
+//	go build -ldflags "-X $PKG.version=v0.2.0 -X $PKG.commit=$(git rev-parse --short HEAD) -X $PKG.buildDate=$(date -u +%Y-%m-%dT%H:%M:%SZ)"
+var (
+	version   = "dev"
+	commit    = "none"
+	buildDate = "unknown"
+)
+
+// Configuration variables (defaults)
+var (
+	// Ticker intervals for different duration ranges
+	tickIntervalFast   = 100 * time.Millisecond // For durations < 1 minute
+	tickIntervalMedium = 500 * time.Millisecond // For durations 1-10 minutes
+	tickIntervalSlow   = 1 * time.Second        // For durations > 10 minutes
+
+	// Durations below which the fast and medium tick intervals are used
+	fastBelow   = time.Minute
+	mediumBelow = 10 * time.Minute
+
+	// Warning threshold for countdown timer, or a percentage of its total
+	// duration when warningPercent is set (e.g. warningThreshold = "10%")
+	warningThreshold = 5 * time.Minute
+	warningPercent   float64
+
+	// Shown by -prompt-start until the first keypress; {duration} is replaced
+	// with the countdown length in words
+	startPrompt = "Press any key to start ({duration})"
+
+	// Start timers in night mode: the display drawn dim (ANSI faint)
+	nightMode = false
+
+	// Columns the inline display may use (0 = the terminal width)
+	inlineWidth = 0
+
+	// Big text glyph dimensions
+	glyphWidth   = 8
+	glyphHeight  = 7
+	glyphSpacing = 1
+
+	// Big text glyph style: "auto", "dots", "block" or "ascii"
+	glyphStyleName = "auto"
+
+	// Save the style picked with 'g' in the session's overrides
+	saveGlyphStyle = false
+
+	// Separator between hours, minutes and seconds
+	separator = ':'
+
+	// Show "3:05" instead of "03:05"
+	trimLeadingZeros = false
+
+	// How fractional seconds are shown: "nearest", "ceil" or "floor"
+	rounding = "nearest"
+
+	// Visual spacing (terminal line height cannot be changed, but we can adjust visual perception)
+
+	// Keyboard input buffer size
+	keyBufferSize = 10
+
+	// Default terminal dimensions
+	defaultTermWidth  = 80
+	defaultTermHeight = 24
+
+	// Auto-restore from last session
+	restoreEnabled = false
+
+	// What `timer` with no duration does: "counter", "default" (run defaultDuration) or "usage"
+	noArgBehavior   = "counter"
+	defaultDuration time.Duration
+
+	// What restoring an already finished session does: "restart", "ignore" or "show"
+	restoreFinished = "restart"
+
+	// How a restored running session treats the time since it was last saved:
+	// "freeze" resumes from the stored elapsed, "continue" counts the downtime
+	restoreDowntime = "freeze"
+
+	// Minimum time between periodic session writes (0 = every display update)
+	autoSaveInterval time.Duration = 0
+
+	// Heartbeat spinner shown on the slow tick tier
+	heartbeatEnabled  = false
+	heartbeatInterval = 250 * time.Millisecond
+	heartbeatFrames   = []string{"|", "/", "-", "\\"}
+
+	// Round elapsed/remaining written to sessions.json to whole seconds
+	roundElapsed = false
+
+	// Ask for confirmation (y/n) before quitting with q/ESC
+	confirmQuit = false
+
+	// Freeze on the final time after q/ESC and exit on the next key
+	reviewOnQuit = false
+
+	// Show the remaining time in the terminal/tab title
+	setTitleEnabled = false
+
+	// Base display colors (ANSI codes); empty means the terminal's default
+	defaultColor = ""
+	timerColor   = ""
+	counterColor = ""
+
+	// Count time while the system is suspended (false treats sleep as paused)
+	countDuringSleep = true
+
+	// Warn at startup when the notification tool is missing
+	notifyCheck = true
+
+	// Draw fullscreen mode on the alternate screen (false draws over the main screen)
+	useAltScreen = true
+
+	// Exit as soon as a countdown finishes (false holds the finished display until a keypress)
+	finishExit = true
+
+	// Spell out the elapsed time in the finish banner and notification
+	friendlyFinish = false
+
+	// Show today's total of finished sessions at the bottom of the fullscreen display
+	showTodayTotal = false
+
+	// Pause a counter after this long without a keypress (0 = never)
+	idleTimeout time.Duration
+
+	// Pause while the terminal loses focus (needs focus reporting support)
+	pauseOnFocusLoss = false
+
+	// Turn on mouse reporting in fullscreen (off keeps mouse text selection working)
+	mouseEnabled = true
+
+	// Countdowns keep running past zero as -mm:ss, beeping every overtimeBeep and
+	// turning a more intense red at each overtimeEscalation point
+	overtimeEnabled    = false
+	overtimeBeep       time.Duration
+	overtimeEscalation = []time.Duration{2 * time.Minute, 5 * time.Minute}
+
+	// Alert points: remaining time for countdowns, elapsed time for counters
+	checkpoints []time.Duration
+
+	// Sound files played when a timer starts, enters the warning period and finishes
+	startSound   = ""
+	warningSound = ""
+	finishSound  = ""
+
+	// Offer a snooze action of this length on the finish notification (0 = off)
+	snoozeDuration time.Duration
+
+	// Read checkpoints and the finish aloud with speakCommand, or the first of
+	// say, spd-say or espeak found when it is empty
+	speakEnabled = false
+	speakCommand = ""
+
+	// Metronome: a sound file played every tickSoundInterval of running time
+	tickSound         = ""
+	tickSoundInterval = time.Second
+
+	// Log start/stop/finish events to syslog
+	syslogEnabled = false
+
+	// Measurement and extra tags of the -influx line-protocol points
+	influxMeasurement = "timer"
+	influxTags        map[string]string
+
+	// Schedule ticks on whole-second boundaries instead of a fixed interval
+	alignToSecond = false
+
+	// Stream for the live display: "stdout" or "stderr"
+	tuiOutput = "stdout"
+)
+
+// Config represents the configuration structure for config.json
+type Config struct {
+	TickIntervalFast   time.Duration `json:"tickIntervalFast"`
+	TickIntervalMedium time.Duration `json:"tickIntervalMedium"`
+	TickIntervalSlow   time.Duration `json:"tickIntervalSlow"`
+	FastBelow          time.Duration `json:"fastBelow"`
+	MediumBelow        time.Duration `json:"mediumBelow"`
+	WarningThreshold   time.Duration `json:"warningThreshold"`
+	GlyphWidth         int           `json:"glyphWidth"`
+	GlyphHeight        int           `json:"glyphHeight"`
+	InlineWidth        int           `json:"inlineWidth"`
+	StartPrompt        string        `json:"startPrompt"`
+	NightMode          bool          `json:"nightMode"`
+	GlyphSpacing       int           `json:"glyphSpacing"`
+	KeyBufferSize      int           `json:"keyBufferSize"`
+	DefaultTermWidth   int           `json:"defaultTermWidth"`
+	DefaultTermHeight  int           `json:"defaultTermHeight"`
+	Restore            bool          `json:"restore"`
+	AutoSaveInterval   time.Duration `json:"autoSaveInterval"`
+	Heartbeat          bool          `json:"heartbeat"`
+	RoundElapsed       bool          `json:"roundElapsed"`
+	ConfirmQuit        bool          `json:"confirmQuit"`
+	ReviewOnQuit       bool          `json:"reviewOnQuit"`
+	SetTitle           bool          `json:"setTitle"`
+	Color              string        `json:"color"`
+	TimerColor         string        `json:"timerColor"`
+	CounterColor       string        `json:"counterColor"`
+	GlyphStyle         string        `json:"glyphStyle"`
+	SaveGlyphStyle     bool          `json:"saveGlyphStyle"`
+	CountDuringSleep   bool          `json:"countDuringSleep"`
+	Separator          string        `json:"separator"`
+	Syslog             bool          `json:"syslog"`
+	InfluxMeasurement  string        `json:"influxMeasurement"`
+	InfluxTags         string        `json:"influxTags"`
+	Rounding           string        `json:"rounding"`
+	TrimLeadingZeros   bool          `json:"trimLeadingZeros"`
+	TUIOutput          string        `json:"tuiOutput"`
+	AlignToSecond      bool          `json:"alignToSecond"`
+	NotifyCheck        bool          `json:"notifyCheck"`
+	RestoreFinished    string        `json:"restoreFinished"`
+	RestoreMode        string        `json:"restoreMode"`
+	NoArgBehavior      string        `json:"noArgBehavior"`
+	DefaultDuration    time.Duration `json:"defaultDuration"`
+	AltScreen          bool          `json:"altScreen"`
+	FinishExit         bool          `json:"finishExit"`
+	FriendlyFinish     bool          `json:"friendlyFinish"`
+	ShowTodayTotal     bool          `json:"showTodayTotal"`
+	IdleTimeout        time.Duration `json:"idleTimeout"`
+	PauseOnFocusLoss   bool          `json:"pauseOnFocusLoss"`
+	Mouse              bool          `json:"mouse"`
+	Overtime           bool          `json:"overtime"`
+	OvertimeBeep       time.Duration `json:"overtimeBeep"`
+	OvertimeEscalation []string      `json:"overtimeEscalation"`
+	Checkpoints        []string      `json:"checkpoints"`
+	StartSound         string        `json:"startSound"`
+	WarningSound       string        `json:"warningSound"`
+	FinishSound        string        `json:"finishSound"`
+	Snooze             time.Duration `json:"snooze"`
+	Speak              bool          `json:"speak"`
+	SpeakCommand       string        `json:"speakCommand"`
+	TickSound          string        `json:"tickSound"`
+	TickSoundInterval  time.Duration `json:"tickSoundInterval"`
+}
+
+// Smallest accepted tick interval; anything faster just burns CPU
+const minTickInterval = 10 * time.Millisecond
+
+// durationKeys are the config fields holding a time.Duration
+var durationKeys = []string{"tickIntervalFast", "tickIntervalMedium", "tickIntervalSlow", "fastBelow", "mediumBelow", "warningThreshold", "autoSaveInterval", "defaultDuration", "idleTimeout", "overtimeBeep", "tickSoundInterval", "snooze"}
+
+// validTickInterval reports whether a configured tick interval is within
+// minTickInterval..max, warning when it isn't
+func validTickInterval(key string, d, max time.Duration) bool {
+	if d < minTickInterval || d > max {
+		warnf("%s %v outside %v-%v; using default", key, d, minTickInterval, max)
+		return false
+	}
+	return true
+}
+
+// parseColor converts a configured color (name, 256-color index or #rrggbb)
+// to its ANSI code for this terminal, keeping fallback (with a warning) when
+// it is invalid
+func parseColor(key, name, fallback string) string {
+	if name == "" {
+		return ""
+	}
+	code, ok := ansiColor(name, colorDepth())
+	if !ok {
+		warnf("unknown %s %q; using default", key, name)
+		return fallback
+	}
+	return code
+}
+
+// systemConfigDir holds the shared, machine-wide configuration
+var systemConfigDir = "/etc"
+
+// configDecoder turns a config file into its top-level fields
+type configDecoder func(data []byte) (map[string]json.RawMessage, error)
+
+// configDecoders maps config file extensions to their decoders, in order of
+// preference when a directory holds more than one
+var configDecoders = []struct {
+	ext    string
+	decode configDecoder
+}{
+	{".json", decodeJSONConfig},
+	{".toml", decodeTOMLConfig},
+}
+
+func decodeJSONConfig(data []byte) (map[string]json.RawMessage, error) {
+	var fields map[string]json.RawMessage
+	if err := json.Unmarshal(data, &fields); err != nil {
+		return nil, err
+	}
+	return fields, nil
+}
+
+// decodeTOMLConfig reads the flat subset of TOML the config needs:
+// key = value lines with strings, booleans and numbers, and # comments
+func decodeTOMLConfig(data []byte) (map[string]json.RawMessage, error) {
+	fields := make(map[string]json.RawMessage)
+	for i, line := range strings.Split(string(data), "\n") {
+		line = strings.TrimSpace(line)
+		if line == "" || line[0] == '#' {
+			continue
+		}
+		key, value, ok := strings.Cut(line, "=")
+		if !ok {
+			return nil, fmt.Errorf("line %d: expected key = value", i+1)
+		}
+		key = strings.Trim(strings.TrimSpace(key), `"`)
+		raw, err := tomlValue(strings.TrimSpace(value))
+		if err != nil {
+			return nil, fmt.Errorf("line %d: %s: %v", i+1, key, err)
+		}
+		fields[key] = raw
+	}
+	return fields, nil
+}
+
+// tomlValue converts a single TOML scalar (with an optional trailing comment) to JSON
+func tomlValue(value string) (json.RawMessage, error) {
+	switch {
+	case strings.HasPrefix(value, `"`):
+		end := strings.LastIndex(value, `"`)
+		if end == 0 {
+			return nil, fmt.Errorf("unterminated string")
+		}
+		str, err := strconv.Unquote(value[:end+1])
+		if err != nil {
+			return nil, fmt.Errorf("invalid string %s", value[:end+1])
+		}
+		return json.Marshal(str)
+	case strings.HasPrefix(value, "'"):
+		end := strings.Index(value[1:], "'")
+		if end < 0 {
+			return nil, fmt.Errorf("unterminated string")
+		}
+		return json.Marshal(value[1 : end+1])
+	}
+	if i := strings.IndexByte(value, '#'); i >= 0 {
+		value = strings.TrimSpace(value[:i])
+	}
+	if value == "true" || value == "false" {
+		return json.RawMessage(value), nil
+	}
+	if _, err := strconv.ParseFloat(strings.ReplaceAll(value, "_", ""), 64); err == nil {
+		return json.RawMessage(strings.ReplaceAll(value, "_", "")), nil
+	}
+	return nil, fmt.Errorf("unsupported value %q", value)
+}
+
+// configProfile selects config.<profile>.json instead of config.json; set from
+// --profile or TIMER_PROFILE before loadConfig
+var configProfile = ""
+
+// findConfigFile returns the file named base within dir in the preferred
+// supported format (JSON, then TOML), or "" when there is none
+func findConfigFile(dir, base string) string {
+	var found []string
+	for _, d := range configDecoders {
+		path := filepath.Join(dir, base+d.ext)
+		if _, err := os.Stat(path); err == nil {
+			found = append(found, path)
+		}
+	}
+	if len(found) > 1 {
+		warnf("using %s; ignoring %s", found[0], strings.Join(found[1:], ", "))
+	}
+	if len(found) == 0 {
+		return ""
+	}
+	return found[0]
+}
+
+// configFile picks the config file within dir: the selected profile's file when
+// present, otherwise config.json (or config.toml). ok reports a profile match.
+func configFile(dir string) (path string, ok bool) {
+	if configProfile != "" {
+		if path := findConfigFile(dir, "config."+configProfile); path != "" {
+			return path, true
+		}
+	}
+	if path := findConfigFile(dir, "config"); path != "" {
+		return path, false
+	}
+	return filepath.Join(dir, "config.json"), false
+}
+
+// userConfigDir returns the user's config directory, falling back to the working
+// directory (where sessions.json lives) when neither XDG_CONFIG_HOME nor HOME is set
+func userConfigDir() string {
+	dir, err := os.UserConfigDir()
+	if err != nil {
+		warnf("cannot determine user config dir: %v; looking in ./go-timer", err)
+		return "."
+	}
+	return dir
+}
+
+// configPaths returns the config files to read, in the order they are applied:
+// the system config first, then the user config (~/.config/go-timer/config.json)
+func configPaths() []string {
+	dirs := []string{filepath.Join(systemConfigDir, "go-timer"), filepath.Join(userConfigDir(), "go-timer")}
+	var paths []string
+	profileFound := false
+	for _, dir := range dirs {
+		path, ok := configFile(dir)
+		paths = append(paths, path)
+		profileFound = profileFound || ok
+	}
+	if configProfile != "" && !profileFound {
+		warnf("config profile %q not found; using config.json", configProfile)
+	}
+	return paths
+}
+
+// setConfigProfile validates and selects a config profile name
+func setConfigProfile(name string) {
+	name, err := normalizeSessionName(name)
+	if err != nil {
+		warnf("invalid config profile: %v; using config.json", err)
+		name = ""
+	}
+	configProfile = name
+}
+
+// loadConfig loads and merges configuration from the system and user config files
+// (config.json, or config.toml when there is no JSON file).
+// The user config wins per field; fields missing from every file keep their defaults.
+func loadConfig() {
+	loadConfigFiles(configPaths())
+}
+
+// loadConfigFiles applies the config files at paths, later ones winning per field
+func loadConfigFiles(paths []string) {
+	merged := make(map[string]json.RawMessage)
+	for _, path := range paths {
+		data, err := os.ReadFile(path)
+		if err != nil {
+			if !errors.Is(err, fs.ErrNotExist) {
+				// Present but unreadable (directory, permissions, ...)
+				warnf("cannot read config %s: %v; using defaults", path, err)
+			}
+			continue // Missing file, use defaults
+		}
+		decode := decodeJSONConfig
+		for _, d := range configDecoders {
+			if filepath.Ext(path) == d.ext {
+				decode = d.decode
+			}
+		}
+		fields, err := decode(data)
+		if err != nil {
+			warnf("invalid config %s: %v; using defaults", path, err)
+			continue
+		}
+		for key, value := range fields {
+			merged[key] = value
+		}
+	}
+
+	// A warningThreshold ending in % is relative to each countdown's duration
+	var thresholdStr string
+	if value, ok := merged["warningThreshold"]; ok && json.Unmarshal(value, &thresholdStr) == nil && strings.HasSuffix(thresholdStr, "%") {
+		delete(merged, "warningThreshold")
+		percent, err := strconv.ParseFloat(strings.TrimSpace(strings.TrimSuffix(thresholdStr, "%")), 64)
+		switch {
+		case err != nil:
+			warnf("invalid warningThreshold %q; using default", thresholdStr)
+		case percent <= 0 || percent > 100:
+			warnf("warningThreshold %q must be above 0%% and at most 100%%; using default", thresholdStr)
+		default:
+			warningPercent = percent
+		}
+	}
+
+	// Duration fields may be written as Go duration strings ("100ms") or nanoseconds
+	for _, key := range durationKeys {
+		var str string
+		if value, ok := merged[key]; !ok || json.Unmarshal(value, &str) != nil {
+			continue
+		}
+		d, err := time.ParseDuration(str)
+		if err != nil {
+			warnf("invalid %s %q: %v; using default", key, str, err)
+			delete(merged, key)
+			continue
+		}
+		merged[key] = json.RawMessage(strconv.FormatInt(int64(d), 10))
+	}
+
+	// Decode one key at a time, so a value of the wrong type loses only that key
+	var config Config
+	for key, value := range merged {
+		field, err := json.Marshal(map[string]json.RawMessage{key: value})
+		if err == nil {
+			err = json.Unmarshal(field, &config)
+		}
+		if err != nil {
+			warnf("invalid %s %s; using default", key, value)
+			delete(merged, key)
+		}
+	}
+	present := func(key string) bool {
+		_, ok := merged[key]
+		return ok
+	}
+
+	// Apply config values that are present, with validation
+	if present("tickIntervalFast") && validTickInterval("tickIntervalFast", config.TickIntervalFast, 1*time.Second) {
+		tickIntervalFast = config.TickIntervalFast
+	}
+	if present("tickIntervalMedium") && validTickInterval("tickIntervalMedium", config.TickIntervalMedium, 1*time.Second) {
+		tickIntervalMedium = config.TickIntervalMedium
+	}
+	if present("tickIntervalSlow") && validTickInterval("tickIntervalSlow", config.TickIntervalSlow, 5*time.Second) {
+		tickIntervalSlow = config.TickIntervalSlow
+	}
+	if present("fastBelow") || present("mediumBelow") {
+		fast, medium := fastBelow, mediumBelow
+		if present("fastBelow") {
+			fast = config.FastBelow
+		}
+		if present("mediumBelow") {
+			medium = config.MediumBelow
+		}
+		if fast > 0 && fast < medium {
+			fastBelow, mediumBelow = fast, medium
+		} else {
+			warnf("fastBelow (%v) must be positive and below mediumBelow (%v); using %v and %v", fast, medium, fastBelow, mediumBelow)
+		}
+	}
+	if present("warningThreshold") {
+		if config.WarningThreshold >= 1*time.Minute && config.WarningThreshold <= 1*time.Hour {
+			warningThreshold = config.WarningThreshold
+			warningPercent = 0
+		}
+	}
+	if present("glyphWidth") && config.GlyphWidth > 0 && config.GlyphWidth <= 20 {
+		glyphWidth = config.GlyphWidth
+		warnf("glyphWidth is replaced by the glyph style's width; choose a glyphStyle instead")
+	}
+	if present("nightMode") {
+		nightMode = config.NightMode
+	}
+	if present("startPrompt") {
+		if strings.TrimSpace(config.StartPrompt) != "" {
+			startPrompt = config.StartPrompt
+		} else {
+			warnf("startPrompt must not be empty; using the default")
+		}
+	}
+	if present("inlineWidth") {
+		if config.InlineWidth >= 0 {
+			inlineWidth = config.InlineWidth
+		} else {
+			warnf("inlineWidth must not be negative; ignoring")
+		}
+	}
+	if present("glyphHeight") && config.GlyphHeight > 0 && config.GlyphHeight <= 20 {
+		glyphHeight = config.GlyphHeight
+		warnf("glyphHeight is replaced by the glyph style's height; choose a glyphStyle instead")
+	}
+	if present("glyphSpacing") && config.GlyphSpacing >= 0 && config.GlyphSpacing <= 5 {
+		glyphSpacing = config.GlyphSpacing
+	}
+	if present("keyBufferSize") && config.KeyBufferSize > 0 && config.KeyBufferSize <= 100 {
+		keyBufferSize = config.KeyBufferSize
+	}
+	if present("defaultTermWidth") && config.DefaultTermWidth > 0 && config.DefaultTermWidth <= 1000 {
+		defaultTermWidth = config.DefaultTermWidth
+	}
+	if present("defaultTermHeight") && config.DefaultTermHeight > 0 && config.DefaultTermHeight <= 1000 {
+		defaultTermHeight = config.DefaultTermHeight
+	}
+	if present("restore") {
+		restoreEnabled = config.Restore
+	}
+	if present("noArgBehavior") {
+		switch config.NoArgBehavior {
+		case "counter", "default", "usage":
+			noArgBehavior = config.NoArgBehavior
+		case "":
+			noArgBehavior = "counter"
+		default:
+			warnf("unknown noArgBehavior %q; using counter", config.NoArgBehavior)
+		}
+	}
+	if present("defaultDuration") {
+		if config.DefaultDuration > 0 {
+			defaultDuration = config.DefaultDuration
+		} else {
+			warnf("defaultDuration must be positive; ignoring")
+		}
+	}
+	if present("restoreFinished") {
+		switch config.RestoreFinished {
+		case "restart", "ignore", "show":
+			restoreFinished = config.RestoreFinished
+		case "":
+			restoreFinished = "restart"
+		default:
+			warnf("unknown restoreFinished %q; using restart", config.RestoreFinished)
+		}
+	}
+	if present("restoreMode") {
+		switch config.RestoreMode {
+		case "freeze", "continue":
+			restoreDowntime = config.RestoreMode
+		case "":
+			restoreDowntime = "freeze"
+		default:
+			warnf("unknown restoreMode %q; using freeze", config.RestoreMode)
+		}
+	}
+	if present("autoSaveInterval") && config.AutoSaveInterval >= 0 && config.AutoSaveInterval <= 1*time.Hour {
+		autoSaveInterval = config.AutoSaveInterval
+	}
+	if present("heartbeat") {
+		heartbeatEnabled = config.Heartbeat
+	}
+	if present("roundElapsed") {
+		roundElapsed = config.RoundElapsed
+	}
+	if present("confirmQuit") {
+		confirmQuit = config.ConfirmQuit
+	}
+	if present("reviewOnQuit") {
+		reviewOnQuit = config.ReviewOnQuit
+	}
+	if present("setTitle") {
+		setTitleEnabled = config.SetTitle
+	}
+	if present("glyphStyle") {
+		if _, ok := glyphStyles[config.GlyphStyle]; ok || config.GlyphStyle == "auto" {
+			glyphStyleName = config.GlyphStyle
+		} else {
+			warnf("unknown glyphStyle %q; using auto", config.GlyphStyle)
+		}
+	}
+	if present("saveGlyphStyle") {
+		saveGlyphStyle = config.SaveGlyphStyle
+	}
+	if present("separator") {
+		if runes := []rune(config.Separator); len(runes) == 1 {
+			separator = runes[0]
+		} else {
+			warnf("separator %q must be a single character; using ':'", config.Separator)
+		}
+	}
+	if present("trimLeadingZeros") {
+		trimLeadingZeros = config.TrimLeadingZeros
+	}
+	if present("rounding") {
+		switch config.Rounding {
+		case "nearest", "ceil", "floor":
+			rounding = config.Rounding
+		case "":
+			rounding = "nearest"
+		default:
+			warnf("unknown rounding %q; using nearest", config.Rounding)
+		}
+	}
+	if present("alignToSecond") {
+		alignToSecond = config.AlignToSecond
+	}
+	if present("tuiOutput") {
+		if config.TUIOutput == "stdout" || config.TUIOutput == "stderr" {
+			tuiOutput = config.TUIOutput
+		} else if config.TUIOutput == "" {
+			tuiOutput = "stdout"
+		} else {
+			warnf("tuiOutput %q must be stdout or stderr; using stdout", config.TUIOutput)
+		}
+	}
+	if present("countDuringSleep") {
+		countDuringSleep = config.CountDuringSleep
+	}
+	if present("notifyCheck") {
+		notifyCheck = config.NotifyCheck
+	}
+	if present("altScreen") {
+		useAltScreen = config.AltScreen
+	}
+	if present("finishExit") {
+		finishExit = config.FinishExit
+	}
+	if present("friendlyFinish") {
+		friendlyFinish = config.FriendlyFinish
+	}
+	if present("showTodayTotal") {
+		showTodayTotal = config.ShowTodayTotal
+	}
+	if present("idleTimeout") {
+		if config.IdleTimeout >= 0 {
+			idleTimeout = config.IdleTimeout
+		} else {
+			warnf("idleTimeout must not be negative; ignoring")
+		}
+	}
+	if present("pauseOnFocusLoss") {
+		pauseOnFocusLoss = config.PauseOnFocusLoss
+	}
+	if present("mouse") {
+		mouseEnabled = config.Mouse
+	}
+	if present("overtime") {
+		overtimeEnabled = config.Overtime
+	}
+	if present("overtimeBeep") {
+		if config.OvertimeBeep >= 0 {
+			overtimeBeep = config.OvertimeBeep
+		} else {
+			warnf("overtimeBeep must not be negative; ignoring")
+		}
+	}
+	if present("overtimeEscalation") {
+		// Reuse the checkpoint parser, then put the points in ascending order
+		if parsed, err := parseCheckpoints(nil, config.OvertimeEscalation); err == nil {
+			slices.Reverse(parsed)
+			overtimeEscalation = parsed
+		} else {
+			warnf("%v; using default overtimeEscalation", err)
+		}
+	}
+	if present("checkpoints") {
+		if parsed, err := parseCheckpoints(nil, config.Checkpoints); err == nil {
+			checkpoints = parsed
+		} else {
+			warnf("%v; ignoring checkpoints", err)
+		}
+	}
+	if present("startSound") {
+		startSound = config.StartSound
+	}
+	if present("warningSound") {
+		warningSound = config.WarningSound
+	}
+	if present("finishSound") {
+		finishSound = config.FinishSound
+	}
+	if present("speak") {
+		speakEnabled = config.Speak
+	}
+	if present("speakCommand") {
+		speakCommand = config.SpeakCommand
+	}
+	if present("snooze") {
+		if config.Snooze >= 0 {
+			snoozeDuration = config.Snooze
+		} else {
+			warnf("snooze must not be negative; ignoring")
+		}
+	}
+	if present("tickSound") {
+		tickSound = config.TickSound
+	}
+	if present("tickSoundInterval") {
+		if config.TickSoundInterval >= minTickInterval {
+			tickSoundInterval = config.TickSoundInterval
+		} else {
+			warnf("tickSoundInterval %v is below the %v minimum; using %v", config.TickSoundInterval, minTickInterval, tickSoundInterval)
+		}
+	}
+	if present("syslog") {
+		syslogEnabled = config.Syslog
+	}
+	if present("influxMeasurement") {
+		if config.InfluxMeasurement != "" {
+			influxMeasurement = config.InfluxMeasurement
+		} else {
+			warnf("influxMeasurement must not be empty; using %q", influxMeasurement)
+		}
+	}
+	if present("influxTags") {
+		if tags, err := parseInfluxTags(config.InfluxTags); err == nil {
+			influxTags = tags
+		} else {
+			warnf("influxTags: %v; ignoring", err)
+		}
+	}
+	if present("color") {
+		defaultColor = parseColor("color", config.Color, defaultColor)
+	}
+	if present("timerColor") {
+		timerColor = parseColor("timerColor", config.TimerColor, timerColor)
+	}
+	if present("counterColor") {
+		counterColor = parseColor("counterColor", config.CounterColor, counterColor)
+	}
+}
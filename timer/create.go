@@ -0,0 +1,95 @@
+package timer
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// parseCreateSpecs parses `timer create` arguments of the form NAME=DURATION
+// into not-yet-started countdown sessions, rejecting bad or repeated names
+func parseCreateSpecs(specs []string) ([]Session, error) {
+	current := now().Format(sessionTimeLayout)
+	seen := make(map[string]bool)
+	var sessions []Session
+	for _, spec := range specs {
+		rawName, rawDuration, ok := strings.Cut(spec, "=")
+		if !ok {
+			return nil, fmt.Errorf("invalid %q: want NAME=DURATION", spec)
+		}
+		name, err := normalizeSessionName(rawName)
+		if err != nil {
+			return nil, err
+		}
+		if name == "" {
+			return nil, fmt.Errorf("invalid %q: missing session name", spec)
+		}
+		if seen[name] {
+			return nil, fmt.Errorf("session %q is given more than once", name)
+		}
+		seen[name] = true
+		duration, err := parseDurationArg(rawDuration)
+		if err != nil {
+			return nil, fmt.Errorf("invalid duration for %q: %w", name, err)
+		}
+		if duration <= 0 {
+			return nil, fmt.Errorf("invalid duration for %q: must be positive", name)
+		}
+		sessions = append(sessions, Session{
+			Start:     current,
+			Current:   current,
+			Elapsed:   formatDuration(0),
+			Remaining: storedDuration(duration),
+			Paused:    true,
+			Mode:      "timer",
+			Name:      name,
+		})
+	}
+	return sessions, nil
+}
+
+// createSessions adds the sessions to sessions.json in a single atomic write,
+// holding the sessions.json lock from the read on so a running timer's save
+// can't land in between. Nothing is written if any of them already exists.
+func createSessions(sessions []Session) error {
+	if !persistSessions {
+		return badInputf("nothing to create with -no-persist")
+	}
+	path, err := filepath.Abs("sessions.json")
+	if err != nil {
+		return err
+	}
+	defer lockSessions(path)()
+	sessionsCache.Lock()
+	defer sessionsCache.Unlock()
+
+	stored := make(map[string]json.RawMessage)
+	data, err := os.ReadFile(path)
+	if err != nil && !errors.Is(err, fs.ErrNotExist) {
+		return fmt.Errorf("failed to read sessions.json: %w", err)
+	}
+	if err == nil {
+		if err := json.Unmarshal(data, &stored); err != nil {
+			return fmt.Errorf("failed to parse sessions.json: %w", err)
+		}
+	}
+	for _, session := range sessions {
+		if _, ok := stored[session.Name]; ok {
+			return badInputf("session %q already exists", session.Name)
+		}
+		raw, err := json.MarshalIndent(session, "  ", "  ")
+		if err != nil {
+			return err
+		}
+		stored[session.Name] = raw
+	}
+	sessionsCache.sessions = nil
+	if err := writeFileAtomic(path, encodeSessions(stored)); err != nil {
+		return fmt.Errorf("failed to write sessions.json: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,248 @@
+package timer
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+	"unicode/utf8"
+)
+
+// displaySeconds converts a duration to the whole seconds shown on screen
+// using the configured rounding ("nearest", "ceil" or "floor")
+func displaySeconds(d time.Duration) int64 {
+	if d < 0 {
+		d = 0
+	}
+	switch rounding {
+	case "ceil":
+		return int64((d + time.Second - 1) / time.Second)
+	case "floor":
+		return int64(d / time.Second)
+	}
+	return int64(d.Round(time.Second) / time.Second)
+}
+
+// overtimeColor returns the color for a countdown this far past zero: red,
+// then one step more intense at each overtimeEscalation point
+func overtimeColor(over time.Duration) string {
+	level := 0
+	for _, point := range overtimeEscalation {
+		if over >= point {
+			level++
+		}
+	}
+	return overtimeColors[min(level, len(overtimeColors)-1)]
+}
+
+func formatHMS(d time.Duration) string {
+	total := int(displaySeconds(d))
+	h := total / 3600
+	m := (total % 3600) / 60
+	s := total % 60
+	if trimLeadingZeros {
+		// Drop the leading zero of the first group only: "3:05", "1:00:00"
+		if h > 0 {
+			return fmt.Sprintf("%d%c%02d%c%02d", h, separator, m, separator, s)
+		}
+		return fmt.Sprintf("%d%c%02d", m, separator, s)
+	}
+	if h > 0 {
+		return fmt.Sprintf("%02d%c%02d%c%02d", h, separator, m, separator, s)
+	}
+	return fmt.Sprintf("%02d%c%02d", m, separator, s)
+}
+
+// fitInline shortens the inline name and time to fit in width columns (0 = no
+// limit): first the name is cut with an ellipsis, then dropped, and as a last
+// resort the time switches to compactTime
+func fitInline(name, timeStr string, width int) (string, string) {
+	timeLen := utf8.RuneCountInString(timeStr)
+	if width <= 0 || name == "" && timeLen <= width {
+		return name, timeStr
+	}
+	if name != "" {
+		nameLen := utf8.RuneCountInString(name)
+		room := width - timeLen - 1
+		if nameLen <= room {
+			return name, timeStr
+		}
+		if room >= 2 {
+			return string([]rune(name)[:room-1]) + "…", timeStr
+		}
+	}
+	if timeLen <= width {
+		return "", timeStr
+	}
+	return "", compactTime(timeStr)
+}
+
+// compactTime abbreviates a formatted time to its largest unit for very narrow
+// inline displays: "1:02:03" becomes "1h02", "05:30" becomes "5m", "00:42" "42s"
+func compactTime(timeStr string) string {
+	sign := ""
+	if strings.HasPrefix(timeStr, "+") || strings.HasPrefix(timeStr, "-") {
+		sign, timeStr = timeStr[:1], timeStr[1:]
+	}
+	groups := strings.Split(timeStr, string(separator))
+	num := func(s string) int {
+		n, _ := strconv.Atoi(s)
+		return n
+	}
+	switch {
+	case len(groups) == 3:
+		return fmt.Sprintf("%s%dh%02d", sign, num(groups[0]), num(groups[1]))
+	case len(groups) == 2 && num(groups[0]) > 0:
+		return fmt.Sprintf("%s%dm", sign, num(groups[0]))
+	case len(groups) == 2:
+		return fmt.Sprintf("%s%ds", sign, num(groups[1]))
+	}
+	return sign + timeStr
+}
+
+// withCaptions frames the big digits for the fullscreen display: the header
+// (session name) above and the description below, each after a blank line
+func withCaptions(bigText, header, description string) string {
+	if header != "" {
+		bigText = header + "\n\n" + bigText
+	}
+	if description != "" {
+		bigText += "\n\n" + description
+	}
+	return bigText
+}
+
+// inlineLine renders the single-line inline display. It starts with \r and
+// ends by clearing to the end of the line, so each update overwrites the last
+// in place whatever its length.
+func inlineLine(name, timeStr, color string) string {
+	var b strings.Builder
+	b.WriteString("\r")
+	if name != "" {
+		b.WriteString(name + " ")
+	}
+	if color != "" {
+		b.WriteString(color + timeStr + resetStyle)
+	} else {
+		b.WriteString(timeStr)
+	}
+	b.WriteString(clearToEOL)
+	return b.String()
+}
+
+// startPromptText fills in the -prompt-start instructions, replacing
+// {duration} with the countdown length in words, or "stopwatch" for a counter
+func startPromptText(template string, duration time.Duration) string {
+	what := "stopwatch"
+	if duration > 0 {
+		what = humanizeDuration(duration)
+	}
+	return strings.ReplaceAll(template, "{duration}", what)
+}
+
+// accessibleDue reports whether accessible mode announces the display second
+// sec: every whole minute, and each of a countdown's final 10 seconds
+func accessibleDue(sec int64, isCounter bool) bool {
+	if !isCounter && sec >= 0 && sec <= 10 {
+		return true
+	}
+	return sec%60 == 0
+}
+
+// spokenTime describes the display time in words for accessible mode
+func spokenTime(displayTime time.Duration, isCounter bool, goal time.Duration, toGoal bool) string {
+	switch {
+	case isCounter && goal > 0 && displayTime > goal:
+		return humanizeDuration(displayTime-goal) + " past the goal"
+	case isCounter && goal > 0 && toGoal:
+		return humanizeDuration(goal-displayTime) + " to the goal"
+	case isCounter:
+		return humanizeDuration(displayTime) + " elapsed"
+	case displayTime < 0:
+		return humanizeDuration(-displayTime) + " over"
+	}
+	return humanizeDuration(displayTime) + " remaining"
+}
+
+// modeColor returns the configured color for a mode ("timer" or "counter"),
+// falling back to the default color when no per-mode color is set
+func modeColor(mode string) string {
+	if mode == "timer" && timerColor != "" {
+		return timerColor
+	}
+	if mode == "counter" && counterColor != "" {
+		return counterColor
+	}
+	return defaultColor
+}
+
+func renderBigTime(timeStr string, termWidth, termHeight int) string {
+	// Calculate if we can fit big text, narrowing the gaps before giving up
+	spacing := glyphSpacing
+	totalWidth := len(timeStr)*(glyphWidth+spacing) - spacing
+	for spacing > 0 && termWidth < totalWidth+4 {
+		spacing--
+		totalWidth = len(timeStr)*(glyphWidth+spacing) - spacing
+	}
+
+	// If too small, return simple text
+	if termWidth < totalWidth+4 || termHeight < glyphHeight+2 {
+		return timeStr
+	}
+
+	// Build the big text line by line
+	lines := make([]string, 0, glyphHeight)
+	for row := 0; row < glyphHeight; row++ {
+		// Pre-allocate capacity for the line builder
+		var line strings.Builder
+		line.Grow(totalWidth)
+
+		for i, ch := range timeStr {
+			glyph, ok := glyphs[ch]
+			if !ok {
+				glyph = glyphs[' ']
+			}
+			line.WriteString(glyph[row])
+			if i < len(timeStr)-1 {
+				line.WriteString(strings.Repeat(" ", spacing))
+			}
+		}
+		lines = append(lines, line.String())
+	}
+
+	return strings.Join(lines, "\n")
+}
+
+func centerText(text string, width, height int) string {
+	lines := strings.Split(text, "\n")
+
+	// Calculate vertical centering
+	vOffset := (height - len(lines)) / 2
+	if vOffset < 0 {
+		vOffset = 0
+	}
+
+	// Pre-allocate builder capacity
+	var result strings.Builder
+	estimatedSize := (vOffset + len(lines)) * (width + 1) // rough estimate
+	result.Grow(estimatedSize)
+
+	// Add vertical padding
+	for i := 0; i < vOffset; i++ {
+		result.WriteString("\n")
+	}
+
+	// Center each line horizontally
+	for _, line := range lines {
+		lineLen := len([]rune(line))
+		hOffset := (width - lineLen) / 2
+		if hOffset < 0 {
+			hOffset = 0
+		}
+		result.WriteString(strings.Repeat(" ", hOffset))
+		result.WriteString(line)
+		result.WriteString("\n")
+	}
+
+	return result.String()
+}
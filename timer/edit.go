@@ -0,0 +1,156 @@
+package timer
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+)
+
+// configTemplate seeds a new config file for `timer edit`; every setting is
+// commented out so the file starts out as the built-in defaults
+const configTemplate = `# go-timer configuration. Uncomment a line to change a setting; the README
+# lists every option. Durations are Go duration strings ("90s", "5m", "1h").
+
+# warningThreshold = "5m"      # or a share of the duration, e.g. "10%"
+# glyphStyle = "auto"          # auto, block, dots or ascii
+# saveGlyphStyle = false       # remember the style picked with g per session
+# separator = ":"
+# trimLeadingZeros = false
+# rounding = "nearest"         # nearest, ceil or floor
+# restore = false
+# noArgBehavior = "counter"    # counter, default or usage
+# defaultDuration = "25m"
+# confirmQuit = false
+# reviewOnQuit = false         # q freezes the final time; the next key exits
+# setTitle = false
+# color = ""
+# timerColor = ""
+# counterColor = ""
+# notifyCheck = true
+# altScreen = true
+# finishExit = true
+# friendlyFinish = false
+# finishSound = ""
+# speak = false
+# speakCommand = ""
+# snooze = "0s"                # e.g. "5m" to offer a snooze button
+# tickSound = ""
+# tickSoundInterval = "1s"
+`
+
+// editorCommand returns the user's editor from $VISUAL or $EDITOR, split into
+// the program and its arguments (e.g. "code --wait"), or nil when neither is set
+func editorCommand() []string {
+	for _, env := range []string{"VISUAL", "EDITOR"} {
+		if fields := strings.Fields(os.Getenv(env)); len(fields) > 0 {
+			return fields
+		}
+	}
+	return nil
+}
+
+// editConfigPath returns the user config file `timer edit` opens: the existing
+// config (or selected profile) file, otherwise a new TOML file so the template
+// can carry comments
+func editConfigPath() string {
+	dir := filepath.Join(userConfigDir(), "go-timer")
+	base := "config"
+	if configProfile != "" {
+		base += "." + configProfile
+	}
+	if path := findConfigFile(dir, base); path != "" {
+		return path
+	}
+	return filepath.Join(dir, base+".toml")
+}
+
+// ensureFile creates path (and its directory) with the given contents unless it
+// already exists; created reports whether it was written
+func ensureFile(path, contents string) (created bool, err error) {
+	if _, err := os.Stat(path); err == nil {
+		return false, nil
+	} else if !errors.Is(err, fs.ErrNotExist) {
+		return false, err
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return false, err
+	}
+	if err := os.WriteFile(path, []byte(contents), 0644); err != nil {
+		return false, err
+	}
+	return true, nil
+}
+
+// validateEditedFile checks a file after editing and returns the problems found:
+// decode errors and invalid values for config files, or entries readSessions
+// had to fix for sessions.json
+func validateEditedFile(path string, sessions bool) []string {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return []string{err.Error()}
+	}
+	if sessions {
+		_, fixes, err := readSessions(data)
+		if err != nil {
+			return []string{err.Error()}
+		}
+		return fixes
+	}
+	// Load just this file and collect what it warns about, so problems in the
+	// system config aren't blamed on it
+	var buf bytes.Buffer
+	orig := warnOut
+	warnOut = &buf
+	loadConfigFiles([]string{path})
+	warnOut = orig
+	var problems []string
+	for _, line := range strings.Split(strings.TrimSpace(buf.String()), "\n") {
+		if line != "" {
+			problems = append(problems, strings.TrimPrefix(line, "Warning: "))
+		}
+	}
+	return problems
+}
+
+// runEdit implements `timer edit [--sessions]`: open the user config (or
+// sessions.json) in $VISUAL/$EDITOR, then report any problems in the result
+func runEdit(sessions bool) error {
+	path, template := editConfigPath(), configTemplate
+	if sessions {
+		path, template = "sessions.json", "{}\n"
+	}
+	created, err := ensureFile(path, template)
+	if err != nil {
+		return fmt.Errorf("cannot create %s: %w", path, err)
+	}
+	if created {
+		fmt.Printf("Created %s\n", path)
+	}
+
+	editor := editorCommand()
+	if editor == nil {
+		// Nothing to open it with: print the path to edit by hand
+		fmt.Println(path)
+		return nil
+	}
+	cmd := exec.Command(editor[0], append(editor[1:], path)...)
+	cmd.Stdin, cmd.Stdout, cmd.Stderr = os.Stdin, os.Stdout, os.Stderr
+	if err := cmd.Run(); err != nil {
+		return fmt.Errorf("editor %s failed: %w", editor[0], err)
+	}
+
+	problems := validateEditedFile(path, sessions)
+	if len(problems) == 0 {
+		fmt.Printf("%s: OK\n", path)
+		return nil
+	}
+	for _, problem := range problems {
+		fmt.Fprintf(os.Stderr, "%s: %s\n", path, problem)
+	}
+	return fmt.Errorf("%s has %d problem(s)", path, len(problems))
+}
@@ -0,0 +1,65 @@
+package timer
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+)
+
+// Exit codes, documented in the README so scripts can tell failures apart
+const (
+	exitFailure  = 1 // Anything not covered below, e.g. the terminal can't be set up
+	exitUsage    = 2 // Bad arguments or input
+	exitNotFound = 3 // The named session does not exist
+	exitIO       = 4 // A file could not be read, parsed or written
+)
+
+// errShowUsage asks for the usage text and exitUsage instead of a message
+var errShowUsage = errors.New("invalid arguments")
+
+// inputError marks an error caused by the command line rather than the system
+type inputError struct{ err error }
+
+func (e inputError) Error() string { return e.err.Error() }
+func (e inputError) Unwrap() error { return e.err }
+
+// badInput marks err as a bad-input error (exitUsage)
+func badInput(err error) error {
+	return inputError{err}
+}
+
+// badInputf formats a bad-input error (exitUsage)
+func badInputf(format string, args ...any) error {
+	return inputError{fmt.Errorf(format, args...)}
+}
+
+// sessionNotFoundError reports a named session missing from sessions.json
+type sessionNotFoundError struct{ name string }
+
+func (e sessionNotFoundError) Error() string { return fmt.Sprintf("session %q not found", e.name) }
+
+// exitCode maps an error to the exit code for its category
+func exitCode(err error) int {
+	var input inputError
+	var notFound sessionNotFoundError
+	var pathErr *fs.PathError
+	var syntaxErr *json.SyntaxError
+	var typeErr *json.UnmarshalTypeError
+	switch {
+	case errors.As(err, &notFound):
+		return exitNotFound
+	case errors.As(err, &input):
+		return exitUsage
+	case errors.As(err, &pathErr), errors.As(err, &syntaxErr), errors.As(err, &typeErr):
+		return exitIO
+	}
+	return exitFailure
+}
+
+// fail reports a user-facing error as "timer: ..." and exits with its code
+func fail(err error) {
+	fmt.Fprintf(os.Stderr, "timer: %v\n", err)
+	os.Exit(exitCode(err))
+}
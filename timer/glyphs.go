@@ -0,0 +1,366 @@
+package timer
+
+import (
+	"os"
+	"strings"
+)
+
+// glyphs is the active glyph set used by renderBigTime, chosen by applyGlyphStyle
+var glyphs = dotGlyphs
+
+// glyphStyle is a named glyph set; every glyph in a set has the same dimensions
+type glyphStyle struct {
+	width  int
+	height int
+	glyphs map[rune][]string
+}
+
+// glyphStyles lists the built-in glyph sets selectable via glyphStyle in config.json
+var glyphStyles = map[string]glyphStyle{
+	"dots":  {width: 8, height: 7, glyphs: dotGlyphs},
+	"block": {width: 6, height: 5, glyphs: blockGlyphs},
+	"ascii": {width: 8, height: 7, glyphs: asciiGlyphs},
+}
+
+// glyphStyleOrder is the order the 'g' key cycles through the glyph styles
+var glyphStyleOrder = []string{"block", "dots", "ascii"}
+
+// activeGlyphStyle names the glyph style currently drawn, once one is applied
+var activeGlyphStyle string
+
+// useGlyphStyle switches to the named built-in style and its dimensions.
+// It reports false, changing nothing, for an unknown style or one without
+// a glyph for the separator.
+func useGlyphStyle(name string) bool {
+	style, ok := glyphStyles[name]
+	if !ok {
+		return false
+	}
+	if _, ok := style.glyphs[separator]; !ok {
+		return false
+	}
+	glyphs = style.glyphs
+	glyphWidth = style.width
+	glyphHeight = style.height
+	activeGlyphStyle = name
+	return true
+}
+
+// cycleGlyphStyle switches to the style after the active one in
+// glyphStyleOrder, skipping any that can't draw the separator, and returns it
+func cycleGlyphStyle() string {
+	start := 0
+	for i, name := range glyphStyleOrder {
+		if name == activeGlyphStyle {
+			start = i + 1
+		}
+	}
+	for i := range glyphStyleOrder {
+		if name := glyphStyleOrder[(start+i)%len(glyphStyleOrder)]; useGlyphStyle(name) {
+			return name
+		}
+	}
+	return activeGlyphStyle
+}
+
+// applyGlyphStyle activates the configured glyph style and adapts the glyph
+// dimensions to it. "auto" picks block glyphs on UTF-8 locales and ASCII otherwise.
+func applyGlyphStyle() {
+	name := glyphStyleName
+	if name == "auto" {
+		name = "ascii"
+		if localeIsUTF8() {
+			name = "block"
+		}
+	}
+	style, ok := glyphStyles[name]
+	if !ok {
+		return // Keep current glyphs
+	}
+	glyphs = style.glyphs
+	glyphWidth = style.width
+	glyphHeight = style.height
+	activeGlyphStyle = name
+
+	// The separator must be renderable in the chosen style
+	if _, ok := glyphs[separator]; !ok {
+		warnf("no %s glyph for separator %q; using ':'", name, separator)
+		separator = ':'
+	}
+}
+
+// localeIsUTF8 reports whether the locale environment (LC_ALL, LC_CTYPE, LANG,
+// in order of precedence) selects a UTF-8 character set
+func localeIsUTF8() bool {
+	for _, key := range []string{"LC_ALL", "LC_CTYPE", "LANG"} {
+		if value := os.Getenv(key); value != "" {
+			value = strings.ToLower(value)
+			return strings.Contains(value, "utf-8") || strings.Contains(value, "utf8")
+		}
+	}
+	return false
+}
+
+// asciiGlyphs mirrors the dot-matrix glyphs using plain ASCII for non-UTF-8 terminals
+var asciiGlyphs = replaceGlyphRune(dotGlyphs, '⬤', '#')
+
+// replaceGlyphRune returns a copy of a glyph set with one rune substituted
+func replaceGlyphRune(set map[rune][]string, old, new rune) map[rune][]string {
+	replaced := make(map[rune][]string, len(set))
+	for ch, rows := range set {
+		newRows := make([]string, len(rows))
+		for i, row := range rows {
+			newRows[i] = strings.ReplaceAll(row, string(old), string(new))
+		}
+		replaced[ch] = newRows
+	}
+	return replaced
+}
+
+// Nothing-inspired dot-matrix glyphs (7x8 size) - compact vertical spacing
+var dotGlyphs = map[rune][]string{
+	'0': {
+		"   ⬤⬤⬤  ",
+		"  ⬤   ⬤ ",
+		" ⬤     ⬤",
+		" ⬤     ⬤",
+		" ⬤     ⬤",
+		"  ⬤   ⬤ ",
+		"   ⬤⬤⬤  ",
+	},
+	'1': {
+		"    ⬤   ",
+		"   ⬤⬤   ",
+		"    ⬤   ",
+		"    ⬤   ",
+		"    ⬤   ",
+		"    ⬤   ",
+		"  ⬤⬤⬤⬤⬤ ",
+	},
+	'2': {
+		"   ⬤⬤⬤  ",
+		"  ⬤   ⬤ ",
+		"      ⬤ ",
+		"     ⬤  ",
+		"    ⬤   ",
+		"   ⬤    ",
+		"  ⬤⬤⬤⬤⬤ ",
+	},
+	'3': {
+		"   ⬤⬤⬤  ",
+		"  ⬤   ⬤ ",
+		"      ⬤ ",
+		"    ⬤⬤  ",
+		"      ⬤ ",
+		"  ⬤   ⬤ ",
+		"   ⬤⬤⬤  ",
+	},
+	'4': {
+		"  ⬤    ⬤",
+		"  ⬤    ⬤",
+		"  ⬤    ⬤",
+		"  ⬤⬤⬤⬤⬤⬤",
+		"       ⬤",
+		"       ⬤",
+		"       ⬤",
+	},
+	'5': {
+		"  ⬤⬤⬤⬤⬤ ",
+		"  ⬤     ",
+		"  ⬤     ",
+		"  ⬤⬤⬤⬤  ",
+		"       ⬤",
+		"  ⬤   ⬤ ",
+		"   ⬤⬤⬤  ",
+	},
+	'6': {
+		"   ⬤⬤⬤⬤ ",
+		"  ⬤     ",
+		"  ⬤     ",
+		"  ⬤⬤⬤⬤  ",
+		"  ⬤   ⬤ ",
+		"  ⬤   ⬤ ",
+		"   ⬤⬤⬤  ",
+	},
+	'7': {
+		"  ⬤⬤⬤⬤⬤ ",
+		"       ⬤",
+		"      ⬤ ",
+		"     ⬤  ",
+		"    ⬤   ",
+		"   ⬤    ",
+		"  ⬤     ",
+	},
+	'8': {
+		"   ⬤⬤⬤  ",
+		"  ⬤   ⬤ ",
+		"  ⬤   ⬤ ",
+		"   ⬤⬤⬤  ",
+		"  ⬤   ⬤ ",
+		"  ⬤   ⬤ ",
+		"   ⬤⬤⬤  ",
+	},
+	'9': {
+		"   ⬤⬤⬤  ",
+		"  ⬤   ⬤ ",
+		"  ⬤   ⬤ ",
+		"   ⬤⬤⬤⬤ ",
+		"      ⬤ ",
+		"  ⬤  ⬤  ",
+		"   ⬤⬤   ",
+	},
+	':': {
+		"        ",
+		"   ⬤⬤   ",
+		"   ⬤⬤   ",
+		"        ",
+		"   ⬤⬤   ",
+		"   ⬤⬤   ",
+		"        ",
+	},
+	'.': {
+		"        ",
+		"        ",
+		"        ",
+		"        ",
+		"        ",
+		"   ⬤⬤   ",
+		"   ⬤⬤   ",
+	},
+	'+': {
+		"        ",
+		"    ⬤   ",
+		"    ⬤   ",
+		"  ⬤⬤⬤⬤⬤ ",
+		"    ⬤   ",
+		"    ⬤   ",
+		"        ",
+	},
+	'-': {
+		"        ",
+		"        ",
+		"        ",
+		"  ⬤⬤⬤⬤⬤ ",
+		"        ",
+		"        ",
+		"        ",
+	},
+	' ': {
+		"        ",
+		"        ",
+		"        ",
+		"        ",
+		"        ",
+		"        ",
+		"        ",
+	},
+}
+
+// Solid block glyphs (6x5 size) - each dot is two full blocks wide
+var blockGlyphs = map[rune][]string{
+	'0': {
+		"██████",
+		"██  ██",
+		"██  ██",
+		"██  ██",
+		"██████",
+	},
+	'1': {
+		"  ██  ",
+		"████  ",
+		"  ██  ",
+		"  ██  ",
+		"██████",
+	},
+	'2': {
+		"██████",
+		"    ██",
+		"██████",
+		"██    ",
+		"██████",
+	},
+	'3': {
+		"██████",
+		"    ██",
+		"██████",
+		"    ██",
+		"██████",
+	},
+	'4': {
+		"██  ██",
+		"██  ██",
+		"██████",
+		"    ██",
+		"    ██",
+	},
+	'5': {
+		"██████",
+		"██    ",
+		"██████",
+		"    ██",
+		"██████",
+	},
+	'6': {
+		"██████",
+		"██    ",
+		"██████",
+		"██  ██",
+		"██████",
+	},
+	'7': {
+		"██████",
+		"    ██",
+		"    ██",
+		"    ██",
+		"    ██",
+	},
+	'8': {
+		"██████",
+		"██  ██",
+		"██████",
+		"██  ██",
+		"██████",
+	},
+	'9': {
+		"██████",
+		"██  ██",
+		"██████",
+		"    ██",
+		"██████",
+	},
+	':': {
+		"      ",
+		"  ██  ",
+		"      ",
+		"  ██  ",
+		"      ",
+	},
+	'.': {
+		"      ",
+		"      ",
+		"      ",
+		"      ",
+		"  ██  ",
+	},
+	'+': {
+		"      ",
+		"  ██  ",
+		"██████",
+		"  ██  ",
+		"      ",
+	},
+	'-': {
+		"      ",
+		"      ",
+		"██████",
+		"      ",
+		"      ",
+	},
+	' ': {
+		"      ",
+		"      ",
+		"      ",
+		"      ",
+		"      ",
+	},
+}
@@ -0,0 +1,94 @@
+package timer
+
+import (
+	"fmt"
+	"io"
+	"net"
+	"os"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Line protocol separators are escaped with a backslash: commas and spaces in
+// the measurement, and equals signs too in tag keys and values
+var (
+	influxMeasurementEscaper = strings.NewReplacer(",", `\,`, " ", `\ `)
+	influxTagEscaper         = strings.NewReplacer(",", `\,`, " ", `\ `, "=", `\=`)
+)
+
+// parseInfluxTags reads influxTags, comma-separated key=value pairs such as
+// "host=laptop,team=core"; an empty list gives no tags
+func parseInfluxTags(list string) (map[string]string, error) {
+	tags := make(map[string]string)
+	for _, pair := range strings.Split(list, ",") {
+		pair = strings.TrimSpace(pair)
+		if pair == "" {
+			continue
+		}
+		key, value, ok := strings.Cut(pair, "=")
+		key, value = strings.TrimSpace(key), strings.TrimSpace(value)
+		if !ok || key == "" || value == "" {
+			return nil, fmt.Errorf("%q is not a key=value tag", pair)
+		}
+		tags[key] = value
+	}
+	return tags, nil
+}
+
+// influxPoint formats the timer state as one line-protocol point: the name and
+// mode tags plus influxTags, the remaining (countdowns only) and elapsed
+// seconds and whether it is paused, stamped in nanoseconds
+func influxPoint(name, mode string, remaining, elapsed int64, paused bool, at time.Time) string {
+	tags := map[string]string{"mode": mode}
+	if name != "" {
+		tags["name"] = name
+	}
+	for key, value := range influxTags {
+		if _, ok := tags[key]; !ok {
+			tags[key] = value
+		}
+	}
+	keys := make([]string, 0, len(tags))
+	for key := range tags {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys) // The order InfluxDB stores them in
+
+	var b strings.Builder
+	b.WriteString(influxMeasurementEscaper.Replace(influxMeasurement))
+	for _, key := range keys {
+		b.WriteString("," + influxTagEscaper.Replace(key) + "=" + influxTagEscaper.Replace(tags[key]))
+	}
+	b.WriteString(" ")
+	if mode != "counter" {
+		b.WriteString("remaining=" + strconv.FormatInt(remaining, 10) + ",")
+	}
+	b.WriteString("elapsed=" + strconv.FormatInt(elapsed, 10))
+	b.WriteString(",paused=" + strconv.FormatBool(paused))
+	b.WriteString(" " + strconv.FormatInt(at.UnixNano(), 10) + "\n")
+	return b.String()
+}
+
+// stdoutInflux writes points to stdout, which stays open when the timer is done
+type stdoutInflux struct{ io.Writer }
+
+func (stdoutInflux) Close() error { return nil }
+
+// openInflux opens the -influx destination: "-" for stdout, otherwise a UDP
+// host:port, with or without a udp:// prefix. Closing it releases the socket.
+func openInflux(dest string) (io.WriteCloser, error) {
+	if dest == "-" {
+		return stdoutInflux{os.Stdout}, nil
+	}
+	addr := strings.TrimPrefix(dest, "udp://")
+	if _, _, err := net.SplitHostPort(addr); err != nil {
+		return nil, badInputf("invalid -influx destination %q: expected - or host:port", dest)
+	}
+	conn, err := net.Dial("udp", addr)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open -influx destination: %w", err)
+	}
+	return conn, nil
+}
@@ -0,0 +1,45 @@
+package timer
+
+import (
+	"os"
+	"time"
+)
+
+// promptToken is the `timer prompt` output for a shell prompt: the time of the
+// named session, or of the most recently written one when name is empty, as a
+// bare "[4:32]". It is empty unless that session is running right now.
+func promptToken(sessions map[string]Session, name string, at time.Time) string {
+	var active *Session
+	for key, session := range sessions {
+		if name != "" && key != name {
+			continue
+		}
+		if !sessionLive(session, at) {
+			continue
+		}
+		if active == nil || session.Current > active.Current {
+			active = &session
+		}
+	}
+	if active == nil {
+		return ""
+	}
+	timeStr, _ := sessionView(*active, at)
+	return "[" + timeStr + "]"
+}
+
+// runPrompt implements `timer prompt [NAME]`, printing promptToken with no
+// escape codes; a missing or unreadable sessions.json prints nothing
+func runPrompt(name string) {
+	data, err := os.ReadFile("sessions.json")
+	if err != nil {
+		return
+	}
+	sessions, _, err := readSessions(data)
+	if err != nil {
+		return
+	}
+	if token := promptToken(sessions, name, now()); token != "" {
+		os.Stdout.WriteString(token + "\n")
+	}
+}
@@ -0,0 +1,61 @@
+package timer
+
+import (
+	"os"
+	"os/exec"
+)
+
+// soundPlayers are the commands tried, in order, to play a sound file
+var soundPlayers = [][]string{
+	{"paplay"},
+	{"aplay", "-q"},
+	{"afplay"},
+}
+
+// soundPlayer returns the first installed sound player, or nil when there is none
+func soundPlayer() []string {
+	for _, player := range soundPlayers {
+		if _, err := exec.LookPath(player[0]); err == nil {
+			return player
+		}
+	}
+	return nil
+}
+
+// playSound plays a sound file in the background. Each cue fails on its own
+// and silently, so a missing player or file never interrupts the timer.
+func playSound(path string) {
+	if path == "" {
+		return
+	}
+	player := soundPlayer()
+	if player == nil {
+		return
+	}
+	cmd := exec.Command(player[0], append(player[1:], path)...)
+	if err := cmd.Start(); err == nil {
+		go cmd.Wait()
+	}
+}
+
+// checkSounds warns at startup about configured sound cues that can't play
+func checkSounds() {
+	if !notifyCheck {
+		return
+	}
+	cues := map[string]string{"startSound": startSound, "warningSound": warningSound, "finishSound": finishSound, "tickSound": tickSound}
+	configured := false
+	for _, key := range []string{"startSound", "warningSound", "finishSound", "tickSound"} {
+		path := cues[key]
+		if path == "" {
+			continue
+		}
+		configured = true
+		if _, err := os.Stat(path); err != nil {
+			warnf("%s %q not found; it will not play", key, path)
+		}
+	}
+	if configured && soundPlayer() == nil {
+		warnf("no sound player (paplay, aplay or afplay) found; sounds are disabled")
+	}
+}
@@ -0,0 +1,81 @@
+package timer
+
+import (
+	"os/exec"
+	"strings"
+)
+
+// speechCommands are the text-to-speech programs tried, in order, when no
+// speakCommand is configured; each is given the text as its last argument
+var speechCommands = [][]string{
+	{"say"},
+	{"spd-say"},
+	{"espeak"},
+}
+
+// speechCommand returns the configured speakCommand split into fields, or the
+// first installed speech program, or nil when there is none
+func speechCommand() []string {
+	if fields := strings.Fields(speakCommand); len(fields) > 0 {
+		return fields
+	}
+	for _, cmd := range speechCommands {
+		if _, err := exec.LookPath(cmd[0]); err == nil {
+			return cmd
+		}
+	}
+	return nil
+}
+
+// speechArgs fills text into a command: every {text} placeholder is replaced,
+// and without one the text is added as the last argument
+func speechArgs(command []string, text string) []string {
+	args := make([]string, 0, len(command)+1)
+	placed := false
+	for _, field := range command[1:] {
+		if strings.Contains(field, "{text}") {
+			field = strings.ReplaceAll(field, "{text}", text)
+			placed = true
+		}
+		args = append(args, field)
+	}
+	if !placed {
+		args = append(args, text)
+	}
+	return args
+}
+
+// speak reads text aloud in the background when speech is enabled, after the
+// timer name if there is one. Like the sound cues it fails silently, so a
+// missing engine never interrupts the timer.
+func speak(name, text string) {
+	if !speakEnabled {
+		return
+	}
+	if name != "" {
+		text = name + ", " + text
+	}
+	command := speechCommand()
+	if command == nil {
+		return
+	}
+	cmd := exec.Command(command[0], speechArgs(command, text)...)
+	if err := cmd.Start(); err == nil {
+		go cmd.Wait()
+	}
+}
+
+// checkSpeech warns at startup when spoken announcements are on but can't play
+func checkSpeech() {
+	if !speakEnabled || !notifyCheck {
+		return
+	}
+	command := speechCommand()
+	if command == nil {
+		warnf("no text-to-speech program (say, spd-say or espeak) found; spoken announcements are disabled")
+		return
+	}
+	if _, err := exec.LookPath(command[0]); err != nil {
+		warnf("speakCommand %q not found; spoken announcements are disabled", command[0])
+	}
+}
@@ -0,0 +1,188 @@
+package timer
+
+import (
+	"fmt"
+	"os"
+	"os/signal"
+	"strings"
+	"syscall"
+	"time"
+	"unicode/utf8"
+)
+
+// splitLiveWindow is how recently a session must have been written to count as
+// running in another terminal; older unfinished sessions are shown as stored
+func splitLiveWindow() time.Duration {
+	return autoSaveInterval + 3*time.Second
+}
+
+// sessionLive reports whether a stored session is running in another terminal
+// right now: neither paused nor finished (a countdown in overtime still runs),
+// and written within splitLiveWindow
+func sessionLive(session Session, at time.Time) bool {
+	current, err := time.ParseInLocation(sessionTimeLayout, session.Current, time.Local)
+	running := !session.Finished || session.Overtime != ""
+	return err == nil && !session.Paused && running && at.Sub(current) <= splitLiveWindow()
+}
+
+// liveExtra is how far a stored session has run since its last write at the
+// given time: zero unless it is running in another terminal right now
+func liveExtra(session Session, at time.Time) time.Duration {
+	if !sessionLive(session, at) {
+		return 0
+	}
+	current, _ := time.ParseInLocation(sessionTimeLayout, session.Current, time.Local)
+	return max(at.Sub(current), 0)
+}
+
+// sessionView works out what a stored session shows at the given time,
+// counting on from its last write while it is running elsewhere
+func sessionView(session Session, at time.Time) (timeStr, color string) {
+	elapsed := parseFormattedDuration(session.Elapsed)
+	extra := liveExtra(session, at)
+	switch {
+	case session.Paused:
+		color = blueColor
+	default:
+		color = modeColor(session.Mode)
+	}
+
+	if session.Mode == "counter" {
+		elapsed += extra
+		if goal := parseFormattedDuration(session.Goal); goal > 0 && elapsed > goal {
+			if !session.Paused {
+				color = yellowColor
+			}
+			return "+" + formatHMS(elapsed-goal), color
+		}
+		return formatHMS(elapsed), color
+	}
+
+	remaining := parseFormattedDuration(session.Remaining) - parseFormattedDuration(session.Overtime) - extra
+	if remaining < 0 && (session.Overtime != "" || overtimeEnabled) {
+		if !session.Paused {
+			color = overtimeColor(-remaining)
+		}
+		return "-" + formatHMS(-remaining), color
+	}
+	total := parseFormattedDuration(session.Elapsed) + parseFormattedDuration(session.Remaining)
+	remaining = max(remaining, 0)
+	if !session.Paused && remaining < session.Overrides.thresholdFor(total) {
+		color = redColor
+	}
+	return formatHMS(remaining), color
+}
+
+// splitPane draws one half of the split view: the label on top and the time
+// in big glyphs, centered within the columns starting at col (1-based)
+func splitPane(label, timeStr, color string, col, width, height int) string {
+	lines := strings.Split(renderBigTime(timeStr, width, height-2), "\n")
+	top := max((height-len(lines))/2, 2)
+
+	var b strings.Builder
+	if n := utf8.RuneCountInString(label); n <= width {
+		b.WriteString(moveCursor(top-1, col+(width-n)/2) + label)
+	}
+	b.WriteString(color)
+	for i, line := range lines {
+		offset := max((width-utf8.RuneCountInString(line))/2, 0)
+		b.WriteString(moveCursor(top+i, col+offset) + line)
+	}
+	if color != "" {
+		b.WriteString(resetStyle)
+	}
+	return b.String()
+}
+
+// viewQuitKeys reads the keyboard for the read-only views and closes the
+// returned channel on q, Q, ESC or Ctrl+C
+func viewQuitKeys() <-chan struct{} {
+	quitCh := make(chan struct{})
+	read := readInput
+	go func() {
+		var decoder inputDecoder
+		buf := make([]byte, 64)
+		for {
+			n, err := read(buf)
+			if err != nil || n == 0 {
+				close(quitCh)
+				return
+			}
+			for _, key := range decoder.feed(buf[:n]) {
+				if key == 'q' || key == 'Q' || key == 0x03 {
+					close(quitCh)
+					return
+				}
+			}
+			if decoder.loneEscape() {
+				close(quitCh)
+				return
+			}
+		}
+	}()
+	return quitCh
+}
+
+// runSplit implements `timer split NAME1 NAME2`: a read-only view of two
+// sessions side by side, following their progress in sessions.json until q
+func runSplit(names [2]string) error {
+	restore, err := enterRawMode()
+	if err != nil {
+		return err
+	}
+	defer restore()
+	fmt.Fprint(tuiOut, altScreen+hideCursor)
+	defer fmt.Fprint(tuiOut, showCursor+mainScreen)
+
+	sigCh := make(chan os.Signal, 1)
+	signal.Notify(sigCh, os.Interrupt, syscall.SIGTERM, syscall.SIGWINCH)
+	defer signal.Stop(sigCh)
+
+	quitCh := viewQuitKeys()
+
+	noColor := os.Getenv("NO_COLOR") != ""
+	draw := func() {
+		width, height := getTerminalSize()
+		half := width / 2
+		var sessions map[string]Session
+		if data, err := os.ReadFile("sessions.json"); err == nil {
+			sessions, _, _ = readSessions(data)
+		}
+		out := clearScreen
+		for i, name := range names {
+			label, timeStr, color := name, "--:--", ""
+			if session, ok := sessions[name]; ok {
+				timeStr, color = sessionView(session, now())
+				if session.Finished {
+					label += " (finished)"
+				} else if session.Paused {
+					label += " (paused)"
+				}
+			} else {
+				label += " (not found)"
+			}
+			if noColor {
+				color = ""
+			}
+			out += splitPane(label, timeStr, color, i*half+1, half, height)
+		}
+		fmt.Fprint(tuiOut, out)
+	}
+
+	ticker := time.NewTicker(tickIntervalMedium)
+	defer ticker.Stop()
+	draw()
+	for {
+		select {
+		case <-ticker.C:
+			draw()
+		case sig := <-sigCh:
+			if sig != syscall.SIGWINCH {
+				return nil
+			}
+			draw() // Reflow to the new size
+		case <-quitCh:
+			return nil
+		}
+	}
+}
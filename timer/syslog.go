@@ -0,0 +1,56 @@
+package timer
+
+import (
+	"fmt"
+	"log/syslog"
+	"time"
+)
+
+// syslogWriter is the part of *syslog.Writer the event log uses
+type syslogWriter interface {
+	Info(m string) error
+	Close() error
+}
+
+// openSyslog connects to the system log; tests replace it with a fake writer
+// or a failure
+var openSyslog = func() (syslogWriter, error) {
+	return syslog.New(syslog.LOG_INFO|syslog.LOG_USER, "go-timer")
+}
+
+// eventLog records timer start/finish events in the system log
+type eventLog struct {
+	writer syslogWriter
+}
+
+// openEventLog connects to syslog when enabled in config. It returns nil when
+// disabled or unavailable; logging problems never stop the timer.
+func openEventLog() *eventLog {
+	if !syslogEnabled {
+		return nil
+	}
+	writer, err := openSyslog()
+	if err != nil {
+		warnf("syslog unavailable: %v", err)
+		return nil
+	}
+	return &eventLog{writer: writer}
+}
+
+// record writes one event line, e.g. "finish name=work mode=timer elapsed=1500.0s"
+func (l *eventLog) record(event, name, mode string, elapsed time.Duration) {
+	if l == nil {
+		return
+	}
+	if name == "" {
+		name = "default"
+	}
+	_ = l.writer.Info(fmt.Sprintf("%s name=%s mode=%s elapsed=%s", event, name, mode, formatDuration(elapsed)))
+}
+
+func (l *eventLog) close() {
+	if l == nil {
+		return
+	}
+	_ = l.writer.Close()
+}
@@ -0,0 +1,276 @@
+package timer
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"strconv"
+	"strings"
+	"syscall"
+	"unsafe"
+
+	"golang.org/x/term"
+)
+
+// Terminal escape codes
+const (
+	clearScreen = "\033[2J"
+	clearLine   = "\033[2K"
+	clearToEOL  = "\033[K"
+	hideCursor  = "\033[?25l"
+	showCursor  = "\033[?25h"
+	altScreen   = "\033[?1049h"
+	mainScreen  = "\033[?1049l"
+	resetStyle  = "\033[0m"
+	blueColor   = "\033[34m"    // Blue text color
+	redColor    = "\033[31m"    // Red text color
+	yellowColor = "\033[33m"    // Yellow text color
+	faintStyle  = "\033[2m"     // Dim text for night mode
+	mouseOn     = "\033[?1000h" // Enable basic mouse tracking
+	mouseOff    = "\033[?1000l" // Disable mouse tracking
+	focusOn     = "\033[?1004h" // Enable focus in/out reports
+	focusOff    = "\033[?1004l" // Disable focus reports
+	pushTitle   = "\033[22;0t"  // Save window title on the terminal's title stack
+	popTitle    = "\033[23;0t"  // Restore the saved window title
+	saveCursor  = "\0337"       // Save cursor position
+	loadCursor  = "\0338"       // Restore the saved cursor position
+	fullScroll  = "\033[r"      // Reset the scroll region to the whole screen
+)
+
+// overtimeColors escalate from plain red to bold red to white on a red background
+var overtimeColors = []string{redColor, "\033[1;31m", "\033[1;97;41m"}
+
+// scrollRegion limits scrolling to rows top..bottom (1-based, inclusive)
+func scrollRegion(top, bottom int) string {
+	return fmt.Sprintf("\033[%d;%dr", top, bottom)
+}
+
+// cornerLine redraws the reserved top row with text right-aligned, leaving the
+// cursor where the rest of the terminal output expects it. visibleLen is the
+// width of text without escape codes.
+func cornerLine(text string, visibleLen, width int) string {
+	col := width - visibleLen + 1
+	if col < 1 {
+		col = 1
+	}
+	return saveCursor + moveCursor(1, 1) + clearLine + moveCursor(1, col) + text + loadCursor
+}
+
+// windowTitle returns the escape sequence that sets the terminal/tab title
+func windowTitle(title string) string {
+	return "\033]0;" + title + "\a"
+}
+
+// Stream the live display is drawn on; stdout unless tuiOutput is "stderr",
+// which keeps stdout free for the final summary
+var (
+	tuiOut io.Writer = os.Stdout
+	tuiFd            = syscall.Stdout
+)
+
+// setTUIOutput selects the stream for the live display ("stdout" or "stderr")
+func setTUIOutput(stream string) {
+	if stream == "stderr" {
+		tuiOut, tuiFd = os.Stderr, syscall.Stderr
+	} else {
+		tuiOut, tuiFd = os.Stdout, syscall.Stdout
+	}
+}
+
+// Keyboard and raw-mode hooks; tests replace them, together with tuiOut, to run
+// the timer without a terminal and capture the frames it draws
+var (
+	// enterRawMode switches the terminal to raw mode and returns how to undo it
+	enterRawMode = func() (restore func(), err error) {
+		oldState, err := setupTerminal()
+		if err != nil {
+			return nil, err
+		}
+		return func() {
+			if err := restoreTerminal(oldState); err != nil {
+				fmt.Fprintf(os.Stderr, "Warning: failed to restore terminal: %v\n", err)
+			}
+		}, nil
+	}
+
+	// readInput blocks until key bytes arrive from the terminal
+	readInput = func(buf []byte) (int, error) {
+		return syscall.Read(int(syscall.Stdin), buf)
+	}
+
+	// stdinIsTerminal reports whether keys can be read from a terminal
+	stdinIsTerminal = func() bool {
+		return term.IsTerminal(int(syscall.Stdin))
+	}
+
+	// tuiIsTerminal reports whether the display stream is attached to a terminal
+	tuiIsTerminal = func() bool {
+		return term.IsTerminal(tuiFd)
+	}
+)
+
+// colorCodes maps color names accepted in config.json to ANSI codes
+var colorCodes = map[string]string{
+	"black":   "\033[30m",
+	"red":     "\033[31m",
+	"green":   "\033[32m",
+	"yellow":  "\033[33m",
+	"blue":    "\033[34m",
+	"magenta": "\033[35m",
+	"cyan":    "\033[36m",
+	"white":   "\033[37m",
+}
+
+// colorDepth reports how many colors the terminal can show: 1<<24 when
+// COLORTERM advertises truecolor, 256 for a *256color TERM, otherwise 16
+func colorDepth() int {
+	if colorterm := strings.ToLower(os.Getenv("COLORTERM")); colorterm == "truecolor" || colorterm == "24bit" {
+		return 1 << 24
+	}
+	if strings.Contains(os.Getenv("TERM"), "256color") {
+		return 256
+	}
+	return 16
+}
+
+// basicPalette approximates the 16 standard terminal colors (xterm defaults)
+var basicPalette = [16][3]int{
+	{0, 0, 0}, {205, 0, 0}, {0, 205, 0}, {205, 205, 0}, {0, 0, 238}, {205, 0, 205}, {0, 205, 205}, {229, 229, 229},
+	{127, 127, 127}, {255, 0, 0}, {0, 255, 0}, {255, 255, 0}, {92, 92, 255}, {255, 0, 255}, {0, 255, 255}, {255, 255, 255},
+}
+
+// cubeLevels are the channel values of the 6x6x6 cube in the 256-color palette
+var cubeLevels = [6]int{0, 95, 135, 175, 215, 255}
+
+// paletteRGB returns the color of a 256-color palette index
+func paletteRGB(index int) (r, g, b int) {
+	switch {
+	case index < 16:
+		c := basicPalette[index]
+		return c[0], c[1], c[2]
+	case index < 232:
+		index -= 16
+		return cubeLevels[index/36], cubeLevels[index/6%6], cubeLevels[index%6]
+	}
+	gray := 8 + (index-232)*10
+	return gray, gray, gray
+}
+
+// colorDistance is the squared distance between two colors
+func colorDistance(r1, g1, b1, r2, g2, b2 int) int {
+	return (r1-r2)*(r1-r2) + (g1-g2)*(g1-g2) + (b1-b2)*(b1-b2)
+}
+
+// nearestPalette returns the index of the palette color closest to r, g, b
+// among the first n (16 or 256)
+func nearestPalette(r, g, b, n int) int {
+	best, bestDistance := 0, -1
+	for i := 0; i < n; i++ {
+		pr, pg, pb := paletteRGB(i)
+		if d := colorDistance(r, g, b, pr, pg, pb); bestDistance < 0 || d < bestDistance {
+			best, bestDistance = i, d
+		}
+	}
+	return best
+}
+
+// paletteCode returns the escape code for a palette index, using the basic
+// SGR codes for the first 16 colors so they work on any terminal
+func paletteCode(index int) string {
+	switch {
+	case index < 8:
+		return fmt.Sprintf("\033[%dm", 30+index)
+	case index < 16:
+		return fmt.Sprintf("\033[%dm", 90+index-8)
+	}
+	return fmt.Sprintf("\033[38;5;%dm", index)
+}
+
+// ansiColor converts a color name, a 256-color index ("208") or a "#rrggbb"
+// truecolor value to an escape code, downgrading to the nearest color the
+// given depth can show. ok is false for anything else.
+func ansiColor(spec string, depth int) (code string, ok bool) {
+	spec = strings.ToLower(strings.TrimSpace(spec))
+	if code, ok := colorCodes[spec]; ok {
+		return code, true
+	}
+	if index, err := strconv.Atoi(spec); err == nil {
+		if index < 0 || index > 255 {
+			return "", false
+		}
+		if depth < 256 && index >= 16 {
+			r, g, b := paletteRGB(index)
+			index = nearestPalette(r, g, b, 16)
+		}
+		return paletteCode(index), true
+	}
+	if len(spec) != 7 || spec[0] != '#' {
+		return "", false
+	}
+	rgb, err := strconv.ParseUint(spec[1:], 16, 32)
+	if err != nil {
+		return "", false
+	}
+	r, g, b := int(rgb>>16), int(rgb>>8&0xff), int(rgb&0xff)
+	switch {
+	case depth > 256:
+		return fmt.Sprintf("\033[38;2;%d;%d;%dm", r, g, b), true
+	case depth == 256:
+		return paletteCode(nearestPalette(r, g, b, 256)), true
+	}
+	return paletteCode(nearestPalette(r, g, b, 16)), true
+}
+
+func moveCursor(row, col int) string {
+	return fmt.Sprintf("\033[%d;%dH", row, col)
+}
+
+// fixNewlines converts \n to \r\n for raw terminal mode
+func fixNewlines(s string) string {
+	return strings.ReplaceAll(s, "\n", "\r\n")
+}
+
+// setupTerminal configures the terminal for raw mode and returns the previous state
+func setupTerminal() (*term.State, error) {
+	fd := int(syscall.Stdin)
+	oldState, err := term.MakeRaw(fd)
+	if err != nil {
+		return nil, fmt.Errorf("failed to set terminal to raw mode: %w", err)
+	}
+	return oldState, nil
+}
+
+// restoreTerminal restores the terminal to its previous state
+func restoreTerminal(oldState *term.State) error {
+	fd := int(syscall.Stdin)
+	if err := term.Restore(fd, oldState); err != nil {
+		return fmt.Errorf("failed to restore terminal: %w", err)
+	}
+	return nil
+}
+
+func getTerminalSize() (width, height int) {
+	// Default fallback
+	width, height = defaultTermWidth, defaultTermHeight
+
+	// Use syscall to get actual terminal size of the display stream
+	type winsize struct {
+		Row    uint16
+		Col    uint16
+		Xpixel uint16
+		Ypixel uint16
+	}
+
+	ws := &winsize{}
+	_, _, errno := syscall.Syscall(syscall.SYS_IOCTL,
+		uintptr(tuiFd),
+		uintptr(syscall.TIOCGWINSZ),
+		uintptr(unsafe.Pointer(ws)))
+
+	if errno == 0 && ws.Col > 0 && ws.Row > 0 {
+		width = int(ws.Col)
+		height = int(ws.Row)
+	}
+
+	return width, height
+}
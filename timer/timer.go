@@ -0,0 +1,1764 @@
+package timer
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"os/signal"
+	"path/filepath"
+	"slices"
+	"sort"
+	"strings"
+	"sync"
+	"syscall"
+	"time"
+	"unicode/utf8"
+)
+
+// Bracketed paste markers sent by the terminal around pasted text
+var (
+	pasteStart = []byte("\033[200~")
+	pasteEnd   = []byte("\033[201~")
+)
+
+// Key codes reported for recognized escape sequences. Raw non-ASCII bytes are
+// never passed through as keys, so the 0x80+ range is free for these.
+const (
+	keyUp byte = 0x80 + iota
+	keyDown
+	keyRight
+	keyLeft
+	keyFocusIn
+	keyFocusOut
+)
+
+// inputDecoder turns raw terminal reads into keys, carrying an incomplete
+// escape sequence over to the next read so one split across reads still
+// decodes as a whole
+type inputDecoder struct {
+	pending []byte
+}
+
+// feed consumes the bytes of one read and returns the keys they complete
+func (d *inputDecoder) feed(data []byte) []byte {
+	var keys []byte
+	for _, b := range data {
+		d.pending = append(d.pending, b)
+		if key, ok := parseInput(d.pending); ok {
+			if key != 0 {
+				keys = append(keys, key)
+			}
+			d.pending = d.pending[:0]
+		}
+	}
+	return keys
+}
+
+// loneEscape reports whether only an ESC is pending, which becomes the Escape
+// key unless the rest of a sequence follows promptly
+func (d *inputDecoder) loneEscape() bool {
+	return len(d.pending) == 1 && d.pending[0] == 0x1b
+}
+
+// reset drops any pending bytes
+func (d *inputDecoder) reset() {
+	d.pending = d.pending[:0]
+}
+
+// parseInput parses accumulated bytes into a key byte or ignores sequences
+func parseInput(seq []byte) (byte, bool) {
+	if len(seq) == 0 {
+		return 0, false
+	}
+	if len(seq) == 1 {
+		if seq[0] == 0x1b {
+			return 0, false // Wait for more or timeout
+		}
+		if seq[0] >= 0x80 {
+			return 0, true // Ignore non-ASCII input
+		}
+		// Single key
+		return seq[0], true
+	}
+	// Check for complete escape sequences
+	if seq[0] == 0x1b {
+		// Bracketed paste: \033[200~ ... \033[201~, swallow everything in between
+		if bytes.HasPrefix(seq, pasteStart) {
+			if len(seq) >= len(pasteStart)+len(pasteEnd) && bytes.HasSuffix(seq, pasteEnd) {
+				return 0, true // Ignore pasted content
+			}
+			return 0, false // Still inside the paste
+		}
+		// Arrow keys: \033[A-D (or \033OA-D in application cursor mode)
+		if len(seq) == 3 && (seq[1] == '[' || seq[1] == 'O') {
+			switch seq[2] {
+			case 'A':
+				return keyUp, true
+			case 'B':
+				return keyDown, true
+			case 'C':
+				return keyRight, true
+			case 'D':
+				return keyLeft, true
+			}
+		}
+		// Focus reports: \033[I (gained) and \033[O (lost)
+		if len(seq) == 3 && seq[1] == '[' && (seq[2] == 'I' || seq[2] == 'O') {
+			if seq[2] == 'I' {
+				return keyFocusIn, true
+			}
+			return keyFocusOut, true
+		}
+		if len(seq) >= 3 && seq[1] == '[' {
+			// X10 mouse report: \033[M plus three raw bytes that may look like keys
+			if seq[2] == 'M' {
+				if len(seq) < 6 {
+					return 0, false
+				}
+				return 0, true // Ignore mouse
+			}
+			// Other CSI sequences (SGR mouse \033[<...M, function keys) run
+			// through parameter bytes up to a final byte in 0x40-0x7E
+			if last := seq[len(seq)-1]; last < 0x40 || last > 0x7E {
+				return 0, false
+			}
+			return 0, true // Ignore mouse and other CSI sequences
+		}
+		// Other escape sequences (e.g., SS3 function keys \033OP), ignore
+		if len(seq) >= 3 && seq[len(seq)-1] >= 0x40 && seq[len(seq)-1] <= 0x7E {
+			return 0, true // Ignore other escapes
+		}
+		// Incomplete, continue
+		return 0, false
+	}
+	// Should not reach here, but if multiple bytes not starting with ESC, treat as single (though unlikely)
+	return seq[0], true
+}
+
+// lineEditor is a small edit sub-mode for typing a value mid-run: printable
+// keys are appended, Backspace deletes, Enter commits and Escape cancels
+type lineEditor struct {
+	active bool
+	buf    []byte
+}
+
+// editResult is what a key did to the line being edited
+type editResult int
+
+const (
+	editContinue editResult = iota
+	editCommit
+	editCancel
+)
+
+// maxEditLen keeps the edited value within a single prompt line
+const maxEditLen = 16
+
+func (e *lineEditor) start() {
+	e.active = true
+	e.buf = e.buf[:0]
+}
+
+func (e *lineEditor) text() string {
+	return string(e.buf)
+}
+
+// feed applies one key, ending the sub-mode on Enter or Escape
+func (e *lineEditor) feed(key byte) editResult {
+	switch {
+	case key == 0x1b:
+		e.active = false
+		return editCancel
+	case key == '\r' || key == '\n':
+		e.active = false
+		return editCommit
+	case key == 0x7f || key == 0x08: // Backspace
+		if len(e.buf) > 0 {
+			e.buf = e.buf[:len(e.buf)-1]
+		}
+	case key >= 0x20 && key < 0x7f && len(e.buf) < maxEditLen:
+		e.buf = append(e.buf, key)
+	}
+	return editContinue
+}
+
+// now returns the current time; tests replace it to control the clock
+var now = time.Now
+
+// elapsedSince returns the time elapsed since start, excluding paused periods
+func elapsedSince(start time.Time, pausedTotal time.Duration, paused bool, pauseStart time.Time) time.Duration {
+	return elapsedAt(now(), start, pausedTotal, paused, pauseStart)
+}
+
+// elapsedAt is elapsedSince at a given time; Timer uses it with its own clock
+func elapsedAt(current, start time.Time, pausedTotal time.Duration, paused bool, pauseStart time.Time) time.Duration {
+	elapsed := current.Sub(start) - pausedTotal
+	if paused {
+		elapsed -= current.Sub(pauseStart)
+	}
+	return elapsed
+}
+
+// Gaps between ticks longer than this are treated as a system suspend
+const sleepGapThreshold = 5 * time.Second
+
+// sleepCompensation detects a system suspend between two ticks: a monotonic gap
+// far longer than the tick interval. When sleep should count, it returns the
+// wall-clock time the monotonic clock missed during it as extra elapsed time;
+// otherwise it returns the gap as time to treat as paused. A wall-clock step
+// on its own (NTP, a manual change) is left alone.
+func sleepCompensation(prev, current time.Time, interval time.Duration, countSleep bool) (extraElapsed, extraPaused time.Duration) {
+	// Round(0) drops the monotonic reading, so Sub compares the wall clocks
+	return gapCompensation(current.Sub(prev), current.Round(0).Sub(prev.Round(0)), interval, countSleep)
+}
+
+// gapCompensation is sleepCompensation for the monotonic and wall time passed
+func gapCompensation(mono, wall, interval time.Duration, countSleep bool) (extraElapsed, extraPaused time.Duration) {
+	if mono-interval <= sleepGapThreshold {
+		return 0, 0
+	}
+	if countSleep {
+		return max(wall-mono, 0), 0
+	}
+	return 0, mono - interval
+}
+
+// startsTimer reports whether a key starts a timer waiting for its first
+// keypress: any key but quitting, and those with a meaning of their own
+// while it waits (editing, adjusting, view toggles)
+func startsTimer(key byte) bool {
+	switch key {
+	case 'q', 'Q', 0x1b, 0x03, // Quit
+		'e', 'E', 'p', 'P', // Edit the time, pause reason
+		't', 'T', 'n', 'N', 'g', 'G': // Time left to goal, night mode, glyph style
+		return false
+	}
+	return timeAdjustment(key) == 0
+}
+
+// timeAdjustment returns how much an arrow key adds to (or removes from) the timer
+func timeAdjustment(key byte) time.Duration {
+	switch key {
+	case keyUp:
+		return time.Minute
+	case keyDown:
+		return -time.Minute
+	case keyRight:
+		return 10 * time.Second
+	case keyLeft:
+		return -10 * time.Second
+	}
+	return 0
+}
+
+// getTickerInterval returns the appropriate ticker interval based on duration
+func getTickerInterval(duration time.Duration) time.Duration {
+	if duration == 0 {
+		// Counter mode - use fast interval for smooth display
+		return tickIntervalFast
+	}
+	if duration < fastBelow {
+		return tickIntervalFast
+	}
+	if duration < mediumBelow {
+		return tickIntervalMedium
+	}
+	return tickIntervalSlow
+}
+
+// alignMargin lands aligned ticks just after a second boundary rather than on it
+const alignMargin = time.Millisecond
+
+// nextSecondDelay returns how long until the displayed time flips to the next
+// whole second (given the rounding mode), so alignToSecond ticks land right on
+// the change instead of drifting with the tick phase. For countdowns it never
+// sleeps past the finish.
+func nextSecondDelay(elapsed, duration time.Duration) time.Duration {
+	offset := time.Duration(0)
+	if rounding == "nearest" {
+		offset = time.Second / 2
+	}
+	mod := func(d time.Duration) time.Duration {
+		return ((d % time.Second) + time.Second) % time.Second
+	}
+	var delay time.Duration
+	if duration == 0 {
+		// Counting up: next time elapsed reaches a flip point
+		delay = time.Second - mod(elapsed-offset)
+	} else {
+		// Counting down: next time remaining drops to a flip point
+		remaining := duration - elapsed
+		delay = mod(remaining - offset)
+		if delay == 0 {
+			delay = time.Second
+		}
+		if remaining > 0 && remaining < delay {
+			delay = remaining
+		}
+	}
+	return delay + alignMargin
+}
+
+// nextTickSound returns the running time at which the metronome next sounds:
+// the first multiple of interval after elapsed
+func nextTickSound(elapsed, interval time.Duration) time.Duration {
+	return (max(elapsed, 0)/interval + 1) * interval
+}
+
+// persistSessions is cleared by --no-persist so a run never touches sessions.json
+var persistSessions = true
+
+// writeSession saves a running timer's state. An Adjust or Reset queued on the
+// stored session by another process is kept until the timer takes it with
+// takeSession, so a save racing `timer +5m` doesn't drop the change.
+func writeSession(session Session) {
+	if !persistSessions {
+		return
+	}
+	path, err := filepath.Abs("sessions.json")
+	if err != nil {
+		return
+	}
+	defer lockSessions(path)()
+	sessionsCache.Lock()
+	defer sessionsCache.Unlock()
+	storeSession(path, session, true)
+}
+
+// lockSessions takes an exclusive lock on the directory holding sessions.json
+// for one read-modify-write, shared with other timer processes. Locking the
+// directory survives the file being replaced by writeFileAtomic; if it can't
+// be locked the write goes ahead unlocked.
+func lockSessions(path string) (unlock func()) {
+	dir, err := os.Open(filepath.Dir(path))
+	if err != nil {
+		return func() {}
+	}
+	if err := syscall.Flock(int(dir.Fd()), syscall.LOCK_EX); err != nil {
+		dir.Close()
+		return func() {}
+	}
+	return func() { dir.Close() } // Closing releases the lock
+}
+
+// storeSession replaces one session in sessions.json at path; the caller holds
+// lockSessions and then sessionsCache. With keepPending, an Adjust or Reset stored
+// on the entry is carried over when session has none of its own.
+func storeSession(path string, session Session, keepPending bool) {
+	key := session.Name
+	if key == "" {
+		key = "default"
+	}
+	// Other sessions are carried over as raw JSON, so only this one is re-encoded,
+	// and the file is only re-parsed when something else has changed it
+	info, err := os.Stat(path)
+	sessions := sessionsCache.sessions
+	switch {
+	case os.IsNotExist(err):
+		sessions = make(map[string]json.RawMessage)
+	case err != nil:
+		return
+	case sessions == nil || path != sessionsCache.path || !info.ModTime().Equal(sessionsCache.modTime) || info.Size() != sessionsCache.size:
+		data, err := os.ReadFile(path)
+		if err != nil {
+			return
+		}
+		sessions = make(map[string]json.RawMessage)
+		_ = json.Unmarshal(data, &sessions)
+	}
+	pending := false
+	if keepPending && session.Adjust == "" && !session.Reset {
+		var stored Session
+		if json.Unmarshal(sessions[key], &stored) == nil && (stored.Adjust != "" || stored.Reset) {
+			session.Adjust, session.Reset = stored.Adjust, stored.Reset
+			pending = true
+		}
+	}
+	raw, err := json.MarshalIndent(session, "  ", "  ")
+	if err != nil {
+		return
+	}
+	sessions[key] = raw
+	if err := writeFileAtomic(path, encodeSessions(sessions)); err != nil {
+		sessionsCache.sessions = nil
+		return
+	}
+	sessionsCache.sessions, sessionsCache.path = sessions, path
+	if info, err := os.Stat(path); err == nil && !pending {
+		sessionsCache.modTime, sessionsCache.size = info.ModTime(), info.Size()
+	} else if err == nil {
+		// Leave the file looking changed, so externalSession reports the
+		// change still waiting to be taken
+		sessionsCache.modTime, sessionsCache.size = time.Time{}, info.Size()
+	} else {
+		sessionsCache.sessions = nil
+	}
+}
+
+// updateSession applies change to a stored session and saves it, all under the
+// sessions.json lock so concurrent writers don't lose each other's changes
+func updateSession(name string, change func(*Session) error) (Session, error) {
+	path, err := filepath.Abs("sessions.json")
+	if err != nil {
+		return Session{}, err
+	}
+	defer lockSessions(path)()
+	session, err := loadSessionLocked(path, name)
+	if err != nil {
+		return Session{}, err
+	}
+	if err := change(&session); err != nil {
+		return Session{}, err
+	}
+	if persistSessions {
+		sessionsCache.Lock()
+		defer sessionsCache.Unlock()
+		storeSession(path, session, false)
+	}
+	return session, nil
+}
+
+// takeSession loads a stored session and clears the Adjust and Reset queued on
+// it, for a run that applies them (or, starting afresh, drops them)
+func takeSession(name string) (Session, error) {
+	var taken Session
+	_, err := updateSession(name, func(session *Session) error {
+		taken = *session
+		session.Adjust, session.Reset = "", false
+		return nil
+	})
+	return taken, err
+}
+
+// rewriteSessions replaces sessions.json at path with the given sessions, e.g.
+// after readSessions corrected hand-edited entries; the caller holds lockSessions
+// from reading the file until here, so no other writer's save is overwritten
+func rewriteSessions(path string, sessions map[string]Session) {
+	if !persistSessions {
+		return
+	}
+	raws := make(map[string]json.RawMessage, len(sessions))
+	for key, session := range sessions {
+		raw, err := json.MarshalIndent(session, "  ", "  ")
+		if err != nil {
+			return
+		}
+		raws[key] = raw
+	}
+	sessionsCache.Lock()
+	defer sessionsCache.Unlock()
+	sessionsCache.sessions = nil
+	_ = writeFileAtomic(path, encodeSessions(raws))
+}
+
+// externalSession returns the stored copy of a session when sessions.json was
+// changed by another process (e.g. `timer +5m`) since this one last wrote it
+func externalSession(name string) (Session, bool) {
+	sessionsCache.Lock()
+	path, modTime, size, known := sessionsCache.path, sessionsCache.modTime, sessionsCache.size, sessionsCache.sessions != nil
+	sessionsCache.Unlock()
+	if !known {
+		return Session{}, false
+	}
+	info, err := os.Stat(path)
+	if err != nil || info.ModTime().Equal(modTime) && info.Size() == size {
+		return Session{}, false
+	}
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return Session{}, false
+	}
+	var sessions map[string]Session
+	if err := json.Unmarshal(data, &sessions); err != nil {
+		return Session{}, false
+	}
+	if name == "" {
+		name = "default"
+	}
+	session, ok := sessions[name]
+	return session, ok
+}
+
+// sessionsCache remembers sessions.json as last written by this process, so
+// the frequent per-tick writes don't re-parse every other session
+var sessionsCache struct {
+	sync.Mutex
+	sessions map[string]json.RawMessage
+	path     string
+	modTime  time.Time
+	size     int64
+}
+
+// encodeSessions lays out sessions.json like json.MarshalIndent (sorted keys,
+// two-space indent) while copying each session's raw JSON verbatim
+func encodeSessions(sessions map[string]json.RawMessage) []byte {
+	keys := make([]string, 0, len(sessions))
+	for key := range sessions {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	var buf bytes.Buffer
+	buf.WriteString("{")
+	for i, key := range keys {
+		if i > 0 {
+			buf.WriteString(",")
+		}
+		quoted, _ := json.Marshal(key)
+		buf.WriteString("\n  ")
+		buf.Write(quoted)
+		buf.WriteString(": ")
+		buf.Write(sessions[key])
+	}
+	if len(keys) > 0 {
+		buf.WriteString("\n")
+	}
+	buf.WriteString("}")
+	return buf.Bytes()
+}
+
+// timerOptions holds the settings for a single timer run
+type timerOptions struct {
+	Duration       time.Duration // 0 means counter mode
+	Fullscreen     bool
+	Paused         bool
+	Name           string
+	InitialElapsed time.Duration
+	Overrides      *SessionOverrides
+	Goal           time.Duration   // Optional goal for counter mode
+	WaitForStart   bool            // Stay paused until the first keypress
+	PromptStart    bool            // While waiting for it, show startPrompt instead of the clock
+	NoClear        bool            // Leave the final frame in the terminal on exit
+	Description    string          // Shown below the digits in fullscreen
+	Night          bool            // Draw the display dim, toggled with 'n'
+	Group          string          // Group whose total `timer watch` shows
+	Pauses         []PauseInterval // Pauses carried over from a restored session
+	PauseAt        []time.Duration // Remaining (timer) or elapsed (counter) times to stop at until a key
+	Corner         bool            // Compact overlay in the top-right corner
+	Checkpoints    []time.Duration // Remaining (timer) or elapsed (counter) times to alert at
+	ShowToGoal     bool            // Counter with a goal shows the time left to it
+	Accessible     bool            // Announce the time in words on new lines instead of redrawing
+	Influx         io.Writer       // Write a line-protocol point each second instead of drawing
+}
+
+func runTimer(opts timerOptions, summaryCh chan<- TimerSummary) error {
+	duration := opts.Duration
+	useFullscreen := opts.Fullscreen
+	initialPaused := opts.Paused
+	name := opts.Name
+	initialElapsed := opts.InitialElapsed
+	overrides := opts.Overrides
+	goal := opts.Goal
+	showToGoal := opts.ShowToGoal && goal > 0
+	description := opts.Description
+	night := opts.Night
+	pauses := append([]PauseInterval(nil), opts.Pauses...)
+	if !initialPaused {
+		closePause(pauses, now())
+	}
+	corner := opts.Corner && !useFullscreen
+	accessible := opts.Accessible && !useFullscreen && !corner
+	if overrides != nil && overrides.GlyphStyle != "" {
+		useGlyphStyle(overrides.GlyphStyle)
+	}
+
+	// Determine if counter mode (duration == 0)
+	isCounter := duration == 0
+	mode := "timer"
+	if isCounter {
+		mode = "counter"
+	}
+
+	// -pause-at points stop the clock once each; those already behind us at
+	// start never fire. autoPaused is set while stopped at one.
+	pauseAt := opts.PauseAt
+	pauseAtFired := make([]bool, len(pauseAt))
+	for i, p := range pauseAt {
+		if isCounter {
+			pauseAtFired[i] = initialElapsed >= p
+		} else {
+			pauseAtFired[i] = duration-initialElapsed <= p
+		}
+	}
+	autoPaused := false
+	var autoPausedAt time.Duration
+
+	// Respect https://no-color.org
+	noColor := os.Getenv("NO_COLOR") != ""
+
+	// Per-session overrides take precedence over the global config
+	threshold := overrides.thresholdFor(duration)
+
+	// Record start/finish in syslog when enabled (before the TUI so warnings stay readable)
+	events := openEventLog()
+	defer events.close()
+
+	// Setup signal handling
+	sigCh := make(chan os.Signal, 1)
+	signal.Notify(sigCh, os.Interrupt, syscall.SIGTERM, syscall.SIGWINCH, syscall.SIGUSR1)
+
+	// Enter alt screen if fullscreen, so the shell's screen and scrollback come back on exit
+	if useFullscreen && useAltScreen {
+		fmt.Fprint(tuiOut, altScreen)
+		defer fmt.Fprint(tuiOut, mainScreen)
+	}
+
+	// Hide cursor (the corner overlay leaves it to the output scrolling beneath)
+	if !corner {
+		fmt.Fprint(tuiOut, hideCursor)
+		defer fmt.Fprint(tuiOut, showCursor)
+	}
+
+	// Reserve the top row for the corner overlay so other output scrolls below it
+	if corner {
+		_, height := getTerminalSize()
+		fmt.Fprint(tuiOut, saveCursor+scrollRegion(2, height)+loadCursor)
+		if opts.NoClear {
+			defer fmt.Fprint(tuiOut, saveCursor+fullScroll+loadCursor)
+		} else {
+			defer fmt.Fprint(tuiOut, saveCursor+fullScroll+moveCursor(1, 1)+clearLine+loadCursor)
+		}
+	}
+
+	// Metrics run fine from a pipe or a service: without a terminal on stdin
+	// there are no keys to read and no raw mode to set up
+	keyless := opts.Influx != nil && !stdinIsTerminal()
+
+	// Configure terminal for raw mode
+	if !keyless {
+		restore, err := enterRawMode()
+		if err != nil {
+			return err
+		}
+		defer restore()
+	}
+
+	// Show the remaining time in the terminal title, restoring the old title on exit
+	useTitle := setTitleEnabled && tuiIsTerminal()
+	if useTitle {
+		fmt.Fprint(tuiOut, pushTitle)
+		defer fmt.Fprint(tuiOut, popTitle)
+	}
+
+	// Enable mouse tracking if fullscreen, unless turned off; it is switched
+	// off on exit either way, in case an earlier run left it on
+	if useFullscreen {
+		if mouseEnabled {
+			fmt.Fprint(tuiOut, mouseOn)
+		}
+		defer fmt.Fprint(tuiOut, mouseOff)
+	}
+
+	// Ask the terminal to report focus changes for auto-pause
+	if pauseOnFocusLoss && tuiIsTerminal() {
+		fmt.Fprint(tuiOut, focusOn)
+		defer fmt.Fprint(tuiOut, focusOff)
+	}
+
+	// Channel for quit signal
+	quitCh := make(chan struct{})
+	defer close(quitCh)
+
+	// Channel for keyboard input; never closed, as the readers stop on quitCh
+	keysCh := make(chan byte, keyBufferSize)
+
+	// Start keyboard reader goroutine (blocking read, low CPU)
+	readCh := make(chan []byte)
+	read := readInput
+	if keyless {
+		read = func([]byte) (int, error) { return 0, io.EOF }
+	}
+	go func() {
+		buf := make([]byte, 64)
+		for {
+			n, err := read(buf)
+			if err != nil || n == 0 {
+				close(readCh)
+				return
+			}
+			select {
+			case readCh <- append([]byte(nil), buf[:n]...):
+			case <-quitCh:
+				return
+			}
+		}
+	}()
+	go func() {
+		var decoder inputDecoder
+		var timer *time.Timer
+		var timerCh <-chan time.Time
+		for {
+			select {
+			case data, ok := <-readCh:
+				if !ok {
+					// error or end of input
+					if timer != nil {
+						timer.Stop()
+					}
+					return
+				}
+				if timer != nil {
+					timer.Stop()
+					timer = nil
+					timerCh = nil
+				}
+				for _, key := range decoder.feed(data) {
+					select {
+					case keysCh <- key:
+					case <-quitCh:
+						return
+					default:
+						// Drop key if channel is full
+					}
+				}
+				if decoder.loneEscape() {
+					// Start timer for ESC
+					timer = time.NewTimer(50 * time.Millisecond)
+					timerCh = timer.C
+				}
+			case <-timerCh:
+				// Timeout, treat as ESC
+				select {
+				case keysCh <- 0x1b:
+				case <-quitCh:
+					return
+				default:
+				}
+				decoder.reset()
+				timer = nil
+				timerCh = nil
+			case <-quitCh:
+				if timer != nil {
+					timer.Stop()
+				}
+				return
+			}
+		}
+	}()
+
+	start := now()
+	if initialElapsed > 0 {
+		start = start.Add(-initialElapsed)
+	}
+	// Use adaptive ticker interval based on duration
+	tickInterval := getTickerInterval(duration)
+	var ticker *time.Ticker = time.NewTicker(tickInterval)
+	defer func() {
+		if ticker != nil {
+			ticker.Stop()
+		}
+	}()
+
+	// Pause state (waiting for the first keypress counts as paused)
+	waitingForStart := opts.WaitForStart
+	var paused = initialPaused || waitingForStart
+	var pauseStart time.Time
+	var totalPausedDuration time.Duration
+	if paused {
+		pauseStart = now()
+	}
+
+	// After the first quit with reviewOnQuit the clock stays frozen on the
+	// final time until the next key closes the timer
+	reviewing := false
+	var reviewElapsed time.Duration
+
+	// effectiveElapsed returns the elapsed time excluding paused periods
+	effectiveElapsed := func() time.Duration {
+		if reviewing {
+			return reviewElapsed
+		}
+		return elapsedSince(start, totalPausedDuration, paused, pauseStart)
+	}
+
+	// Last time the session was persisted, used to honor autoSaveInterval
+	var lastSave time.Time
+
+	// Saves while running happen in the background; they are all done by the
+	// time runTimer returns. Each is numbered so one that loses the race to a
+	// newer snapshot is dropped instead of overwriting it.
+	var saves sync.WaitGroup
+	var saveMu sync.Mutex
+	var queuedSave, writtenSave int
+	defer saves.Wait()
+	saveAsync := func(session Session) {
+		queuedSave++
+		seq := queuedSave
+		saves.Add(1)
+		go func() {
+			defer saves.Done()
+			saveMu.Lock()
+			defer saveMu.Unlock()
+			if seq < writtenSave {
+				return
+			}
+			writtenSave = seq
+			writeSession(session)
+		}()
+	}
+
+	// saveNow writes synchronously, after any background save still pending
+	saveNow := func(session Session) {
+		saves.Wait()
+		writeSession(session)
+	}
+
+	// Time of the previous tick, used to detect system sleep
+	var lastTick time.Time
+
+	// Time of the previous -pause-at check: a point passed since then was
+	// reached by the clock running, one further back was jumped over
+	pauseCheck := now()
+
+	// snapshot builds the session state to persist for the given effective elapsed time
+	snapshot := func(current time.Time, elapsed time.Duration) Session {
+		session := Session{
+			Start:       start.Format(sessionTimeLayout),
+			Current:     current.Format(sessionTimeLayout),
+			Elapsed:     storedDuration(elapsed),
+			Paused:      paused || reviewing, // The review display is frozen too
+			Mode:        mode,
+			Name:        name,
+			Finished:    false,
+			Inline:      !useFullscreen,
+			Overrides:   overrides,
+			Description: description,
+			Night:       night,
+			Group:       opts.Group,
+			Pauses:      slices.Clone(pauses), // closePause and the reason editor change it in place
+			PauseAt:     pendingPausePoints(pauseAt, pauseAtFired),
+		}
+		if goal > 0 {
+			session.Goal = formatDuration(goal)
+			session.ShowToGoal = showToGoal
+		}
+		if !isCounter {
+			remaining := duration - elapsed
+			if remaining < 0 {
+				if overtimeEnabled {
+					// Past zero counts as finished, even while overtime keeps running
+					session.Overtime = storedDuration(-remaining)
+					session.Finished = true
+				}
+				remaining = 0
+			}
+			session.Remaining = storedDuration(remaining)
+		}
+		return session
+	}
+
+	// Cache for rendered output
+	var lastRenderedSec int64 = -1
+	var cachedOutput string
+
+	// Finished sessions from today, shown at the bottom of the fullscreen display
+	showToday := showTodayTotal && persistSessions
+	var today time.Duration
+	if showToday {
+		today = loadTodayTotal(name)
+	}
+
+	// Accessible mode prints a line of words on minute boundaries, through a
+	// countdown's final seconds, and whenever the time jumps or pauses
+	announced := false
+	var announcedSec, prevSec int64
+	var announcedPaused bool
+
+	promptShown := false
+
+	// render formats the given display time and caches the output
+	render := func(displayTime time.Duration) {
+		if opts.Influx != nil {
+			// Metrics only: seconds as the display shows them, negative in overtime
+			sec := displaySeconds(displayTime)
+			if displayTime < 0 {
+				sec = -displaySeconds(-displayTime)
+			}
+			elapsedSec := sec
+			if !isCounter {
+				elapsedSec = displaySeconds(duration) - sec
+			}
+			fmt.Fprint(opts.Influx, influxPoint(name, mode, sec, elapsedSec, paused, now()))
+			return
+		}
+		if opts.PromptStart && waitingForStart {
+			// Instructions instead of the clock until the first keypress
+			text := startPromptText(startPrompt, duration-initialElapsed)
+			switch {
+			case accessible:
+				if !promptShown {
+					fmt.Fprint(tuiOut, text+"\r\n")
+				}
+				cachedOutput = ""
+			case useFullscreen:
+				width, height := getTerminalSize()
+				cachedOutput = centerText(text, width, height)
+			case corner:
+				width, _ := getTerminalSize()
+				cachedOutput = cornerLine(" "+text+" ", utf8.RuneCountInString(text)+2, width)
+			default:
+				width := overrides.inlineWidth()
+				if width == 0 {
+					termWidth, _ := getTerminalSize()
+					width = termWidth - 1
+				}
+				label := ""
+				if room := width - utf8.RuneCountInString(text); room > 1 {
+					label, _ = fitInline(name, "", room)
+				}
+				cachedOutput = inlineLine(label, text, "")
+			}
+			promptShown = true
+			return
+		}
+		if accessible {
+			sec := displaySeconds(displayTime)
+			if displayTime < 0 {
+				sec = -displaySeconds(-displayTime)
+			}
+			jumped := sec-prevSec > 1 || prevSec-sec > 1
+			prevSec = sec
+			if announced && paused == announcedPaused && !jumped && (sec == announcedSec || !accessibleDue(sec, isCounter)) {
+				return
+			}
+			announced, announcedSec, announcedPaused = true, sec, paused
+			line := spokenTime(displayTime, isCounter, goal, showToGoal)
+			if paused {
+				if reason := pauseReason(pauses); reason != "" {
+					line = "paused for " + reason + ", " + line
+				} else {
+					line = "paused, " + line
+				}
+			}
+			if name != "" {
+				line = name + ": " + line
+			}
+			fmt.Fprint(tuiOut, line+"\r\n")
+			return
+		}
+
+		timeStr := formatHMS(displayTime)
+		overGoal := isCounter && goal > 0 && displayTime > goal
+		if overGoal {
+			// Counter past its goal - show the overtime
+			timeStr = "+" + formatHMS(displayTime-goal)
+		} else if isCounter && showToGoal {
+			// Counter toggled to show what's left until its goal
+			timeStr = formatHMS(goal - displayTime)
+		} else if displayTime < 0 {
+			// Countdown in overtime
+			timeStr = "-" + formatHMS(-displayTime)
+		}
+
+		if useTitle {
+			title := timeStr
+			if name != "" {
+				title += " " + name
+			}
+			fmt.Fprint(tuiOut, windowTitle(title))
+		}
+
+		// Determine color based on state and time remaining
+		var color string
+		if paused {
+			color = blueColor
+		} else if overGoal {
+			color = yellowColor
+		} else if !isCounter && displayTime < 0 {
+			// Overtime - red that intensifies the longer it runs
+			color = overtimeColor(-displayTime)
+		} else if !isCounter && displayTime < threshold {
+			// Only show red warning in timer mode
+			color = redColor
+		} else {
+			color = modeColor(mode)
+		}
+		if noColor {
+			color = ""
+		}
+		if night {
+			// Faint is an intensity, not a color, so it applies under NO_COLOR too
+			color = faintStyle + color
+		}
+
+		if useFullscreen {
+			// Get terminal size
+			width, height := getTerminalSize()
+
+			// Render big text, leaving room for the name, the description
+			// and the reason for the current pause
+			below := description
+			if reason := pauseReason(pauses); paused && reason != "" {
+				if below != "" {
+					below += "\n"
+				}
+				below += "paused: " + reason
+			}
+			captionLines := 0
+			if name != "" {
+				captionLines += 2
+			}
+			if below != "" {
+				captionLines += 2 + strings.Count(below, "\n")
+			}
+			bigText := withCaptions(renderBigTime(timeStr, width, height-captionLines), name, below)
+
+			// Center the output first
+			centeredText := centerText(bigText, width, height)
+
+			// Apply color (paused = blue, <5min = red, over goal = yellow, else = mode color)
+			if color != "" {
+				cachedOutput = color + centeredText + resetStyle
+			} else {
+				cachedOutput = centeredText
+			}
+			if showToday {
+				cachedOutput += moveCursor(height, 1) + "today " + formatHMS(today)
+			}
+		} else if corner {
+			// Compact overlay, right-aligned on the reserved top row
+			label := " " + timeStr + " "
+			if name != "" {
+				label = " " + name + label
+			}
+			text := label
+			if color != "" {
+				text = color + label + resetStyle
+			}
+			width, _ := getTerminalSize()
+			cachedOutput = cornerLine(text, utf8.RuneCountInString(label), width)
+		} else {
+			// Single compact line, updated in place, kept short enough not to wrap
+			width := overrides.inlineWidth()
+			if width == 0 {
+				termWidth, _ := getTerminalSize()
+				width = termWidth - 1
+			}
+			label := name
+			if reason := pauseReason(pauses); paused && reason != "" {
+				label = strings.TrimSpace(label + " (" + reason + ")")
+			}
+			label, timeStr := fitInline(label, timeStr, width)
+			cachedOutput = inlineLine(label, timeStr, color)
+		}
+	}
+
+	// Initial render - show the starting time immediately
+	var initialDisplayTime time.Duration
+	if isCounter {
+		// Counter mode - start at 00:00, or the continued total
+		initialDisplayTime = initialElapsed
+	} else {
+		// Timer mode - show full duration
+		initialDisplayTime = duration
+	}
+
+	// Render initial state
+	render(initialDisplayTime)
+	if useFullscreen {
+		fmt.Fprint(tuiOut, clearScreen+moveCursor(1, 1)+fixNewlines(cachedOutput))
+	} else {
+		fmt.Fprint(tuiOut, cachedOutput)
+	}
+
+	// Write initial session state
+	var initialElapsedForDisplay time.Duration
+	if isCounter {
+		initialElapsedForDisplay = initialDisplayTime
+	} else {
+		initialElapsedForDisplay = initialElapsed
+	}
+	initialSession := snapshot(start, initialElapsedForDisplay)
+	saveAsync(initialSession)
+	lastSave = now()
+	events.record("start", name, mode, initialElapsedForDisplay)
+	playSound(startSound)
+
+	// Checkpoints fire once each when crossed; those already behind us at start never fire
+	checkpoints := opts.Checkpoints
+	checkpointFired := make([]bool, len(checkpoints))
+	for i, cp := range checkpoints {
+		if isCounter {
+			checkpointFired[i] = initialElapsed >= cp
+		} else {
+			checkpointFired[i] = duration-initialElapsed <= cp
+		}
+	}
+
+	// Countdowns with overtime enabled keep running past zero; inOvertime is set
+	// once it's reached (already, for a restored overtime session)
+	inOvertime := !isCounter && overtimeEnabled && initialElapsed >= duration
+	nextBeep := overtimeBeep
+	if inOvertime && overtimeBeep > 0 {
+		nextBeep = ((initialElapsed-duration)/overtimeBeep + 1) * overtimeBeep
+	}
+
+	// The warning cue plays once when a countdown crosses into the warning period
+	warned := !isCounter && duration-initialElapsed < threshold
+
+	lastRenderedSec = int64(initialDisplayTime.Seconds())
+
+	// Heartbeat spinner on the slow tick tier so long countdowns don't look frozen
+	var heartbeatCh <-chan time.Time
+	heartbeatFrame := 0
+	if heartbeatEnabled && tickInterval == tickIntervalSlow && !accessible {
+		heartbeatTicker := time.NewTicker(heartbeatInterval)
+		defer heartbeatTicker.Stop()
+		heartbeatCh = heartbeatTicker.C
+	}
+	heartbeat := func() string {
+		if heartbeatCh == nil || corner {
+			return ""
+		}
+		frame := " " // Blank while paused or frozen for review
+		if !paused && !reviewing {
+			frame = heartbeatFrames[heartbeatFrame%len(heartbeatFrames)]
+		}
+		if useFullscreen {
+			width, height := getTerminalSize()
+			return moveCursor(height, width) + frame
+		}
+		return " " + frame
+	}
+
+	// Prompt line: quit confirmation while waiting for y/n when confirmQuit is
+	// set, or the value being typed after 'e' (or the pause reason after 'p')
+	confirmingQuit := false
+	var editor lineEditor
+	editingReason := false
+	editLabel := "remaining"
+	if isCounter {
+		editLabel = "elapsed"
+	}
+	prompt := func() string {
+		var text string
+		switch {
+		case confirmingQuit:
+			text = "quit? (y/n)"
+		case autoPaused && paused:
+			text = "paused at " + formatHMS(autoPausedAt) + " - press any key to continue"
+		case reviewing:
+			text = "stopped - press any key to exit"
+		case editor.active && editingReason:
+			text = "pause reason: " + editor.text()
+		case editor.active:
+			text = "set " + editLabel + ": " + editor.text()
+		default:
+			return ""
+		}
+		if accessible {
+			return text + "\r\n"
+		}
+		if useFullscreen {
+			_, height := getTerminalSize()
+			return moveCursor(height, 1) + clearLine + text
+		}
+		if corner {
+			width, _ := getTerminalSize()
+			return cornerLine(" "+text+" ", len(text)+2, width)
+		}
+		return "  " + text
+	}
+
+	// adjustBy adds delta to the countdown target or the counter's elapsed time
+	adjustBy := func(delta time.Duration) {
+		elapsed := effectiveElapsed()
+		if isCounter {
+			// Counter mode - shift the start so elapsed never drops below zero
+			if elapsed+delta < 0 {
+				delta = -elapsed
+			}
+			start = start.Add(-delta)
+		} else {
+			// Timer mode - change the target, finishing on the next tick if it passes
+			duration += delta
+			if overtimeEnabled {
+				duration = max(duration, 0)
+			} else if duration < elapsed {
+				duration = elapsed
+			}
+		}
+		// Persist the state change right away
+		lastSave = now()
+		saveAsync(snapshot(lastSave, effectiveElapsed()))
+		// Force re-render
+		lastRenderedSec = -1
+	}
+
+	// togglePause pauses or resumes as of at, switching to the slow ticker while paused
+	togglePause := func(at time.Time) {
+		if paused {
+			// Unpause
+			totalPausedDuration += at.Sub(pauseStart)
+			paused = false
+			autoPaused = false
+			closePause(pauses, at)
+			// Restart ticker with normal interval
+			if ticker != nil {
+				ticker.Stop()
+			}
+			ticker = time.NewTicker(tickInterval)
+		} else {
+			// Pause
+			paused = true
+			pauseStart = at
+			pauses = openPause(pauses, at)
+			// Switch to slow ticker to reduce CPU usage
+			if ticker != nil {
+				ticker.Stop()
+			}
+			ticker = time.NewTicker(tickIntervalSlow)
+		}
+		// Persist the state change right away
+		lastSave = now()
+		saveAsync(snapshot(lastSave, effectiveElapsed()))
+		// Force re-render
+		lastRenderedSec = -1
+	}
+	// Set when losing focus paused the timer, so regaining it resumes only then
+	focusPaused := false
+
+	// Counters pause after idleTimeout without input, as of the last keypress
+	lastInput := now()
+	idlePaused := false
+
+	// Ticks aligned to second boundaries replace the fixed interval when enabled
+	var alignTimer *time.Timer
+	if alignToSecond {
+		alignTimer = time.NewTimer(time.Hour)
+		defer alignTimer.Stop()
+	}
+
+	// The metronome follows running time on its own timer, so it keeps its
+	// rhythm whatever the redraw cadence and stays silent while paused
+	var metronome *time.Timer
+	if tickSound != "" {
+		metronome = time.NewTimer(time.Hour)
+		defer metronome.Stop()
+	}
+
+	for {
+		tickCh := ticker.C
+		if alignTimer != nil && !paused {
+			alignTimer.Reset(nextSecondDelay(effectiveElapsed(), duration))
+			tickCh = alignTimer.C
+		}
+		var metronomeCh <-chan time.Time
+		if metronome != nil && !paused && !reviewing {
+			elapsed := effectiveElapsed()
+			metronome.Reset(nextTickSound(elapsed, tickSoundInterval) - elapsed)
+			metronomeCh = metronome.C
+		}
+		select {
+		case <-metronomeCh:
+			playSound(tickSound)
+
+		case <-heartbeatCh:
+			if paused || reviewing {
+				continue
+			}
+			heartbeatFrame++
+			if useFullscreen {
+				fmt.Fprint(tuiOut, heartbeat())
+			} else {
+				fmt.Fprint(tuiOut, cachedOutput+heartbeat())
+			}
+
+		case sig := <-sigCh:
+			if sig == syscall.SIGWINCH {
+				// Terminal resized - force re-render
+				lastRenderedSec = -1
+				if corner {
+					_, height := getTerminalSize()
+					fmt.Fprint(tuiOut, saveCursor+scrollRegion(2, height)+loadCursor)
+				}
+				continue
+			}
+			if sig == syscall.SIGUSR1 {
+				// `kill -USR1 PID` toggles pause like the space key, starting a
+				// timer that waits for its first keypress
+				if reviewing {
+					continue
+				}
+				if waitingForStart {
+					waitingForStart = false
+					start = start.Add(now().Sub(pauseStart))
+					pauseStart = now()
+				}
+				idlePaused, focusPaused = false, false
+				lastInput = now()
+				togglePause(now())
+				continue
+			}
+			// Handle interrupt/terminate signals
+			end := now()
+			effectiveDuration := effectiveElapsed()
+			// Write final session state
+			signalSession := snapshot(end, effectiveDuration)
+			saveNow(signalSession) // Synchronous write for final state
+			events.record("stop", name, mode, effectiveDuration)
+			summaryCh <- TimerSummary{
+				Start:    start,
+				End:      end,
+				Duration: effectiveDuration,
+				Mode:     mode,
+				Finished: inOvertime,
+				Name:     name,
+				Total:    duration,
+			}
+			return nil
+
+		case key := <-keysCh:
+			if key == keyFocusIn || key == keyFocusOut {
+				// Auto-pause while the terminal is in the background; focus
+				// changes are not input, so they neither wake nor delay idle pauses
+				if !pauseOnFocusLoss || waitingForStart || reviewing || idlePaused {
+					continue
+				}
+				if key == keyFocusOut && !paused {
+					focusPaused = true
+					togglePause(now())
+				} else if key == keyFocusIn && paused && focusPaused {
+					focusPaused = false
+					togglePause(now())
+				}
+				continue
+			}
+			lastInput = now()
+			if idlePaused {
+				// Any key wakes an idle-paused counter and is otherwise ignored
+				idlePaused = false
+				if paused {
+					togglePause(lastInput)
+				}
+				continue
+			}
+			if editor.active && key != 0x03 {
+				// Typing a new value - Enter applies it, Escape cancels
+				if editor.feed(key) == editContinue {
+					if useFullscreen {
+						fmt.Fprint(tuiOut, prompt())
+					} else {
+						fmt.Fprint(tuiOut, "\r"+clearLine+cachedOutput+prompt())
+					}
+					continue
+				}
+				if !editor.active && key != 0x1b && editingReason {
+					// Label the ongoing pause
+					if n := len(pauses); n > 0 && pauses[n-1].End == "" {
+						pauses[n-1].Reason = editor.text()
+						lastSave = now()
+						saveAsync(snapshot(lastSave, effectiveElapsed()))
+					}
+				} else if !editor.active && key != 0x1b {
+					if value, err := parseDurationArg(editor.text()); err == nil {
+						elapsed := effectiveElapsed()
+						if isCounter {
+							start = start.Add(elapsed - value)
+						} else {
+							duration = elapsed + value
+						}
+						lastSave = now()
+						saveAsync(snapshot(lastSave, effectiveElapsed()))
+					}
+				}
+				editingReason = false
+				if !useFullscreen && !corner {
+					fmt.Fprint(tuiOut, "\r"+clearLine+cachedOutput)
+				}
+				// Force re-render
+				lastRenderedSec = -1
+				continue
+			}
+			if autoPaused && paused && key != 'q' && key != 'Q' && key != 0x1b && key != 0x03 {
+				// Stopped at a -pause-at point: any key carries on
+				key = 0x20
+			}
+			if reviewing {
+				// Any key closes the frozen display
+				key = 'q'
+			} else if confirmingQuit {
+				// Waiting for quit confirmation - y confirms, anything else cancels
+				confirmingQuit = false
+				if key != 'y' && key != 'Y' {
+					if !useFullscreen && !corner {
+						fmt.Fprint(tuiOut, "\r"+clearLine+cachedOutput)
+					}
+					// Force re-render
+					lastRenderedSec = -1
+					continue
+				}
+				key = 'q'
+			} else if confirmQuit && (key == 'q' || key == 'Q' || key == 0x1b) {
+				// Ask before quitting
+				confirmingQuit = true
+				if useFullscreen {
+					fmt.Fprint(tuiOut, prompt())
+				} else {
+					fmt.Fprint(tuiOut, cachedOutput+prompt())
+				}
+				continue
+			}
+
+			if waitingForStart && startsTimer(key) {
+				// First keypress starts counting; move the start past the wait
+				waitingForStart = false
+				start = start.Add(now().Sub(pauseStart))
+				pauseStart = now()
+				key = 0x20
+			}
+
+			// Handle keyboard input
+			switch key {
+			case 0x20: // Space key - pause/unpause
+				focusPaused = false
+				togglePause(now())
+
+			case 'p', 'P': // Pause (if running) and type the reason for it
+				if waitingForStart {
+					break
+				}
+				if !paused {
+					focusPaused = false
+					togglePause(now())
+				} else if len(pauses) == 0 || pauses[len(pauses)-1].End != "" {
+					// Paused since start: label it from now on
+					pauses = openPause(pauses, now())
+				}
+				editingReason = true
+				editor.start()
+				if useFullscreen {
+					fmt.Fprint(tuiOut, prompt())
+				} else {
+					fmt.Fprint(tuiOut, cachedOutput+prompt())
+				}
+
+			case 'e', 'E': // Type an exact remaining (timer) or elapsed (counter) time
+				editor.start()
+				if useFullscreen {
+					fmt.Fprint(tuiOut, prompt())
+				} else {
+					fmt.Fprint(tuiOut, cachedOutput+prompt())
+				}
+
+			case keyUp, keyDown, keyRight, keyLeft: // Arrow keys - adjust time
+				adjustBy(timeAdjustment(key))
+
+			case 't', 'T': // Counter with a goal - toggle elapsed / left to goal
+				if isCounter && goal > 0 {
+					showToGoal = !showToGoal
+					lastSave = now()
+					saveAsync(snapshot(lastSave, effectiveElapsed()))
+					// Force re-render
+					lastRenderedSec = -1
+				}
+
+			case 'g', 'G': // Fullscreen - cycle the glyph style
+				if useFullscreen {
+					style := cycleGlyphStyle()
+					if saveGlyphStyle {
+						saved := SessionOverrides{}
+						if overrides != nil {
+							saved = *overrides
+						}
+						saved.GlyphStyle = style
+						overrides = &saved
+						lastSave = now()
+						saveAsync(snapshot(lastSave, effectiveElapsed()))
+					}
+					// Force re-render at the new glyph size
+					lastRenderedSec = -1
+				}
+
+			case 'n', 'N': // Toggle night mode
+				night = !night
+				lastSave = now()
+				saveAsync(snapshot(lastSave, effectiveElapsed()))
+				// Force re-render
+				lastRenderedSec = -1
+
+			case 'q', 'Q', 0x1b: // q, Q, or ESC - quit
+				if reviewOnQuit && !reviewing {
+					// Freeze on the final time and exit on the next key
+					reviewElapsed = effectiveElapsed()
+					reviewing = true
+					if useFullscreen || accessible {
+						fmt.Fprint(tuiOut, prompt())
+					} else {
+						fmt.Fprint(tuiOut, cachedOutput+prompt())
+					}
+					continue
+				}
+				fmt.Fprint(tuiOut, "\r\nquitting...\r\n")
+				end := now()
+				effectiveDuration := effectiveElapsed()
+				// Write final session state
+				quitSession := snapshot(end, effectiveDuration)
+				saveNow(quitSession) // Synchronous write for final state
+				events.record("stop", name, mode, effectiveDuration)
+				summaryCh <- TimerSummary{
+					Start:    start,
+					End:      end,
+					Duration: effectiveDuration,
+					Mode:     mode,
+					Finished: inOvertime,
+					Total:    duration,
+				}
+				return nil
+
+			case 0x03: // Ctrl+C
+				end := now()
+				effectiveDuration := effectiveElapsed()
+				// Write final session state
+				ctrlcSession := snapshot(end, effectiveDuration)
+				saveNow(ctrlcSession) // Synchronous write for final state
+				events.record("stop", name, mode, effectiveDuration)
+				summaryCh <- TimerSummary{
+					Start:    start,
+					End:      end,
+					Duration: effectiveDuration,
+					Mode:     mode,
+					Finished: inOvertime,
+					Total:    duration,
+				}
+				return nil
+			}
+
+		case <-tickCh:
+			// Account for a system suspend since the previous tick
+			tickTime := now()
+			if !paused && !lastTick.IsZero() {
+				extraElapsed, extraPaused := sleepCompensation(lastTick, tickTime, getTickerInterval(duration), countDuringSleep)
+				start = start.Add(-extraElapsed)
+				totalPausedDuration += extraPaused
+			}
+			lastTick = tickTime
+
+			// Stop counting idle time; it's excluded back to the last keypress
+			if isCounter && idleTimeout > 0 && !paused && !reviewing && tickTime.Sub(lastInput) >= idleTimeout {
+				idlePaused = true
+				focusPaused = false
+				togglePause(lastInput)
+			}
+
+			// Pick up `timer reset` and "+5m"/"-2m" adjustments made from another terminal
+			if stored, ok := externalSession(name); ok && !reviewing && (stored.Reset || stored.Adjust != "") {
+				// Take them under the lock, so one queued meanwhile isn't lost
+				taken, _ := takeSession(name)
+				if taken.Reset && isCounter {
+					start = now()
+					totalPausedDuration = 0
+					pauses = nil
+					pauseStart = start
+					lastInput = start
+					lastRenderedSec = -1
+				}
+				if taken.Adjust != "" {
+					adjustBy(parseFormattedDuration(taken.Adjust))
+				}
+				saveNow(snapshot(now(), effectiveElapsed()))
+			}
+
+			// Calculate effective elapsed time (excluding paused duration)
+			elapsed := effectiveElapsed()
+
+			// Stop at the next -pause-at point, as of the moment it was reached;
+			// one jumped over with e, the arrows or `timer +5m` is only marked fired
+			sinceCheck := tickTime.Sub(pauseCheck)
+			pauseCheck = tickTime
+			if i := crossedPausePoint(pauseAt, pauseAtFired, elapsed, duration); i >= 0 {
+				pauseAtFired[i] = true
+				reachedAt := pauseAt[i]
+				if !isCounter {
+					reachedAt = duration - pauseAt[i]
+				}
+				if !paused && elapsed-reachedAt <= sinceCheck {
+					togglePause(start.Add(totalPausedDuration + reachedAt))
+					autoPaused, autoPausedAt = true, pauseAt[i]
+					elapsed = effectiveElapsed()
+					fmt.Fprint(tuiOut, "\a")
+					if accessible {
+						fmt.Fprint(tuiOut, prompt())
+					}
+				}
+			}
+
+			var displayTime time.Duration
+			var currentSec int64
+
+			if isCounter {
+				// Counter mode - count up
+				displayTime = elapsed
+				currentSec = displaySeconds(elapsed)
+				for i, cp := range checkpoints {
+					if !checkpointFired[i] && elapsed >= cp {
+						checkpointFired[i] = true
+						fmt.Fprint(tuiOut, "\a")
+						go notify(name, formatHMS(cp)+" elapsed")
+						speak(name, spokenTime(cp, true, 0, false))
+					}
+				}
+				// Never exit automatically in counter mode
+			} else {
+				// Timer mode - count down
+				if elapsed >= duration && overtimeEnabled {
+					// Past zero - keep counting the overtime, escalating as it grows
+					if !inOvertime {
+						inOvertime = true
+						fmt.Fprint(tuiOut, "\a")
+						events.record("finish", name, mode, elapsed)
+						playSound(finishSound)
+						go notify(name, "Time's up - counting overtime")
+						speak(name, "time's up")
+						nextBeep = overtimeBeep
+					}
+					over := elapsed - duration
+					if overtimeBeep > 0 && over >= nextBeep {
+						fmt.Fprint(tuiOut, "\a")
+						nextBeep = (over/overtimeBeep + 1) * overtimeBeep
+					}
+					displayTime = -over
+					currentSec = -displaySeconds(over) - 2 // Distinct from the countdown seconds and the -1 sentinel
+				} else if elapsed >= duration {
+					// Timer finished
+					end := now()
+					effectiveDuration := effectiveElapsed()
+					banner := "finished!"
+					message := "Timer finished!"
+					if friendlyFinish {
+						banner = "finished after " + humanizeDuration(effectiveDuration) + "!"
+						message = "Finished after " + humanizeDuration(effectiveDuration) + "."
+					}
+					if showToday {
+						banner += " today " + formatHMS(today+effectiveDuration)
+					}
+					var drawFinished func() // Redraws the held display, e.g. after a resize
+					if finishExit && opts.NoClear {
+						// Leave the finished frame at zero behind, with the banner below it
+						render(0)
+						if useFullscreen {
+							fmt.Fprint(tuiOut, clearScreen+moveCursor(1, 1)+fixNewlines(cachedOutput))
+						} else {
+							fmt.Fprint(tuiOut, cachedOutput)
+						}
+						fmt.Fprint(tuiOut, "\r\n"+banner+"\r\n")
+					} else if finishExit {
+						fmt.Fprint(tuiOut, "\r\n"+banner+"\r\n")
+					} else {
+						// Hold the finished display at zero until a key is pressed
+						banner += " (press any key)"
+						drawFinished = func() {
+							render(0)
+							if useFullscreen {
+								_, height := getTerminalSize()
+								fmt.Fprint(tuiOut, clearScreen+moveCursor(1, 1)+fixNewlines(cachedOutput)+moveCursor(height, 1)+clearLine+banner)
+							} else {
+								fmt.Fprint(tuiOut, cachedOutput+"  "+banner)
+							}
+						}
+						drawFinished()
+					}
+					// Write final session state
+					finalSession := snapshot(end, effectiveDuration)
+					finalSession.Paused = false
+					finalSession.Finished = true
+					saveNow(finalSession) // Synchronous write for final state
+					events.record("finish", name, mode, effectiveDuration)
+					playSound(finishSound)
+					speak(name, "time's up")
+					// The snooze notification waits for a click in the background,
+					// so keys and signals still answer while it is up
+					var snoozeCh chan bool
+					notifyCtx, cancelNotify := context.WithCancel(context.Background())
+					defer cancelNotify()
+					if snoozeDuration > 0 {
+						snoozeCh = make(chan bool, 1)
+						go func() { snoozeCh <- notifySnooze(notifyCtx, name, message, snoozeDuration) }()
+					} else {
+						notify(name, message)
+					}
+					// Wait for a snooze answer, the acknowledging keypress or a quit signal
+					snoozed := false
+					for waiting := !finishExit || snoozeCh != nil; waiting; {
+						keyed := false
+						select {
+						case snoozed = <-snoozeCh:
+							snoozeCh = nil
+							waiting = !finishExit && !snoozed
+						case key := <-keysCh:
+							keyed = key != keyFocusIn && key != keyFocusOut
+							waiting = !keyed
+						case sig := <-sigCh:
+							keyed = sig != syscall.SIGWINCH && sig != syscall.SIGUSR1
+							waiting = !keyed
+							if sig == syscall.SIGWINCH && drawFinished != nil {
+								drawFinished()
+							}
+						}
+						if keyed {
+							fmt.Fprint(tuiOut, "\r\n")
+						}
+					}
+					summaryCh <- TimerSummary{
+						Start:    start,
+						End:      end,
+						Duration: effectiveDuration,
+						Mode:     "timer",
+						Finished: true,
+						Name:     name,
+						Total:    duration,
+						Snoozed:  snoozed,
+					}
+					return nil
+				} else {
+					inOvertime = false // Re-arm after time is added back
+					displayTime = duration - elapsed
+					currentSec = displaySeconds(displayTime)
+				}
+				for i, cp := range checkpoints {
+					if !checkpointFired[i] && displayTime <= cp {
+						checkpointFired[i] = true
+						fmt.Fprint(tuiOut, "\a")
+						go notify(name, formatHMS(cp)+" remaining")
+						speak(name, spokenTime(cp, false, 0, false))
+					}
+				}
+				if displayTime >= threshold {
+					warned = false // Re-arm after time is added back
+				} else if !warned {
+					warned = true
+					playSound(warningSound)
+				}
+			}
+
+			// Re-render when second changes OR when paused state changes
+			if currentSec != lastRenderedSec || lastRenderedSec == -1 {
+				lastRenderedSec = currentSec
+
+				// Write current session to file, at most once per autoSaveInterval
+				currentTime := now()
+				if autoSaveInterval == 0 || currentTime.Sub(lastSave) >= autoSaveInterval {
+					lastSave = currentTime
+					session := snapshot(currentTime, elapsed)
+					saveAsync(session) // Write asynchronously to avoid blocking UI
+				}
+
+				render(displayTime)
+			}
+
+			// Output the cached rendering (fix newlines for raw mode); accessible
+			// announcements are written by render itself
+			if accessible {
+				continue
+			}
+			if useFullscreen {
+				fmt.Fprint(tuiOut, clearScreen+moveCursor(1, 1)+fixNewlines(cachedOutput)+heartbeat()+prompt())
+			} else {
+				fmt.Fprint(tuiOut, cachedOutput+heartbeat()+prompt())
+			}
+		}
+	}
+}
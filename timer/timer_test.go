@@ -0,0 +1,3614 @@
+package timer
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"os"
+	"path/filepath"
+	"runtime"
+	"strconv"
+	"strings"
+	"sync"
+	"syscall"
+	"testing"
+	"time"
+	"unicode/utf8"
+)
+
+func TestParseDurationArg(t *testing.T) {
+	valid := map[string]time.Duration{
+		"5":     5 * time.Second,
+		"2.5":   2500 * time.Millisecond,
+		"90s":   90 * time.Second,
+		"1h30m": 90 * time.Minute,
+	}
+	for arg, want := range valid {
+		got, err := parseDurationArg(arg)
+		if err != nil || got != want {
+			t.Errorf("parseDurationArg(%q) = %v, %v; want %v", arg, got, err, want)
+		}
+	}
+	for _, arg := range []string{"0", "0s", "-5s", "-10", "abc", ""} {
+		if got, err := parseDurationArg(arg); err == nil {
+			t.Errorf("parseDurationArg(%q) = %v; want error", arg, got)
+		}
+	}
+}
+
+func TestParseDurationArgCasualInput(t *testing.T) {
+	cases := []struct {
+		arg  string
+		want time.Duration
+	}{
+		{"5 S", 5 * time.Second},
+		{"2.5  Sec", 2500 * time.Millisecond},
+		{"5M", 5 * time.Minute},
+		{"  90  ", 90 * time.Second},
+		{"10secs", 10 * time.Second},
+		{"1 min", time.Minute},
+		{"3 Mins", 3 * time.Minute},
+		{"2hr", 2 * time.Hour},
+		{"1 HRS", time.Hour},
+		{"1h 30m", 90 * time.Minute},
+		{"1 hr 30 min", 90 * time.Minute},
+		{"1H30M", 90 * time.Minute},
+		{"250 MS", 250 * time.Millisecond},
+		{"\t45s\n", 45 * time.Second},
+	}
+	for _, c := range cases {
+		got, err := parseDurationArg(c.arg)
+		if err != nil || got != c.want {
+			t.Errorf("parseDurationArg(%q) = %v, %v; want %v", c.arg, got, err, c.want)
+		}
+	}
+	for _, arg := range []string{"5 3", "1h 30", "5 minutes later", "5 mo", "1.2.3s", "s", "m5", "5 m s", " ", "5d"} {
+		if got, err := parseDurationArg(arg); err == nil {
+			t.Errorf("parseDurationArg(%q) = %v; want error", arg, got)
+		}
+	}
+}
+
+func TestParseRelativeArg(t *testing.T) {
+	valid := map[string]time.Duration{
+		"+5m": 5 * time.Minute,
+		"-2m": -2 * time.Minute,
+		"+90": 90 * time.Second,
+	}
+	for arg, want := range valid {
+		if got, err := parseRelativeArg(arg); err != nil || got != want {
+			t.Errorf("parseRelativeArg(%q) = %v, %v; want %v", arg, got, err, want)
+		}
+	}
+	for _, arg := range []string{"5m", "+", "+0", "-abc", "++5m"} {
+		if got, err := parseRelativeArg(arg); err == nil {
+			t.Errorf("parseRelativeArg(%q) = %v; want error", arg, got)
+		}
+	}
+}
+
+func TestPauseIntervals(t *testing.T) {
+	at := time.Date(2024, 1, 1, 12, 0, 0, 0, time.Local)
+	var pauses []PauseInterval
+	closePause(pauses, at) // Nothing open: no-op
+
+	pauses = openPause(pauses, at)
+	if pauseReason(pauses) != "" {
+		t.Fatalf("new pause should have no reason")
+	}
+	pauses[0].Reason = "lunch"
+	if got := pauseReason(pauses); got != "lunch" {
+		t.Fatalf("expected the ongoing pause's reason, got %q", got)
+	}
+	closePause(pauses, at.Add(30*time.Minute))
+	want := PauseInterval{Start: "2024-01-01:12-00-00", End: "2024-01-01:12-30-00", Reason: "lunch"}
+	if pauses[0] != want {
+		t.Fatalf("got %+v, want %+v", pauses[0], want)
+	}
+	if pauseReason(pauses) != "" {
+		t.Fatalf("a finished pause has no current reason")
+	}
+
+	// Intervals survive a round trip through sessions.json
+	data, err := json.Marshal(Session{Mode: "counter", Pauses: pauses})
+	if err != nil {
+		t.Fatalf("marshal: %v", err)
+	}
+	var session Session
+	if err := json.Unmarshal(data, &session); err != nil || len(session.Pauses) != 1 || session.Pauses[0] != want {
+		t.Fatalf("round trip lost the pauses: %s (%v)", data, err)
+	}
+}
+
+func TestFormatParsed(t *testing.T) {
+	cases := []struct {
+		d      time.Duration
+		format string
+		want   string
+	}{
+		{90 * time.Second, "human", "90\t90.0s"},
+		{1500 * time.Millisecond, "seconds", "1.5\t1.5s"},
+		{-2 * time.Minute, "human", "-120\t-120.0s"},
+		{150 * time.Second, "json", `{"seconds":150,"duration":"150.0s"}`},
+	}
+	for _, c := range cases {
+		if got := formatParsed(c.d, c.format); got != c.want {
+			t.Errorf("formatParsed(%v, %q) = %q, want %q", c.d, c.format, got, c.want)
+		}
+	}
+}
+
+func TestAdjustSession(t *testing.T) {
+	withTempDir(t, func(dir string) {
+		cwd, err := os.Getwd()
+		if err != nil {
+			t.Fatalf("Getwd: %v", err)
+		}
+		defer os.Chdir(cwd)
+		if err := os.Chdir(dir); err != nil {
+			t.Fatalf("Chdir: %v", err)
+		}
+
+		if _, err := adjustSession("tea", time.Minute); err == nil {
+			t.Fatalf("expected error without a stored session")
+		}
+		writeSession(Session{Name: "tea", Mode: "timer", Elapsed: "60.0s", Remaining: "120.0s"})
+		if _, err := adjustSession("tea", 5*time.Minute); err != nil {
+			t.Fatalf("adjustSession: %v", err)
+		}
+		session, err := adjustSession("tea", -2*time.Minute)
+		if err != nil || session.Adjust != "180.0s" {
+			t.Fatalf("expected adjustments to accumulate to 180.0s, got %q, %v", session.Adjust, err)
+		}
+		if stored, _ := loadSession("tea"); stored.Adjust != "180.0s" || stored.Remaining != "120.0s" {
+			t.Fatalf("expected the adjustment to be queued, got %+v", stored)
+		}
+
+		writeSession(Session{Name: "done", Mode: "timer", Finished: true})
+		if _, err := adjustSession("done", time.Minute); err == nil {
+			t.Fatalf("expected error for a finished session")
+		}
+	})
+}
+
+func TestPendingSessionChanges(t *testing.T) {
+	chdirTemp(t)
+	writeSession(Session{Name: "tea", Mode: "timer", Remaining: "120.0s"})
+	adjustSession("tea", 5*time.Minute)
+
+	// The running timer's next save keeps the queued change until it takes it
+	writeSession(Session{Name: "tea", Mode: "timer", Remaining: "110.0s"})
+	if stored, ok := externalSession("tea"); !ok || stored.Adjust != "300.0s" || stored.Remaining != "110.0s" {
+		t.Fatalf("expected the adjustment kept and reported, got %+v, %v", stored, ok)
+	}
+	taken, err := takeSession("tea")
+	if err != nil || taken.Adjust != "300.0s" {
+		t.Fatalf("expected to take the adjustment, got %+v, %v", taken, err)
+	}
+	writeSession(Session{Name: "tea", Mode: "timer", Remaining: "400.0s"})
+	if stored, _ := loadSession("tea"); stored.Adjust != "" {
+		t.Fatalf("expected the taken adjustment gone, got %q", stored.Adjust)
+	}
+
+	// Adjustments cancelling out leave nothing queued
+	adjustSession("tea", time.Minute)
+	adjustSession("tea", -time.Minute)
+	writeSession(Session{Name: "tea", Mode: "timer", Remaining: "390.0s"})
+	if stored, _ := loadSession("tea"); stored.Adjust != "" {
+		t.Fatalf("expected no adjustment after +1m -1m, got %q", stored.Adjust)
+	}
+
+	// Saves racing the CLI never drop an adjustment
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		for i := 0; i < 50; i++ {
+			writeSession(Session{Name: "tea", Mode: "timer", Remaining: "390.0s"})
+		}
+	}()
+	for i := 0; i < 20; i++ {
+		if _, err := adjustSession("tea", time.Second); err != nil {
+			t.Fatalf("adjustSession: %v", err)
+		}
+	}
+	<-done
+	if stored, _ := loadSession("tea"); stored.Adjust != "20.0s" {
+		t.Fatalf("expected all 20 adjustments queued, got %q", stored.Adjust)
+	}
+}
+
+func TestExitCode(t *testing.T) {
+	_, readErr := os.ReadFile(filepath.Join(t.TempDir(), "sessions.json"))
+	_, durationErr := parseDurationArg("soon")
+	var syntax map[string]Session
+	parseErr := json.Unmarshal([]byte("{"), &syntax)
+	cases := []struct {
+		err  error
+		want int
+	}{
+		{badInput(durationErr), exitUsage},
+		{badInputf("-goal only applies to counter mode"), exitUsage},
+		{fmt.Errorf("restore: %w", sessionNotFoundError{"tea"}), exitNotFound},
+		{fmt.Errorf("failed to read sessions.json: %w", readErr), exitIO},
+		{fmt.Errorf("failed to parse sessions.json: %w", parseErr), exitIO},
+		{errors.New("failed to set terminal to raw mode"), exitFailure},
+	}
+	for _, c := range cases {
+		if got := exitCode(c.err); got != c.want {
+			t.Errorf("exitCode(%v) = %d, want %d", c.err, got, c.want)
+		}
+	}
+	if got := badInput(durationErr).Error(); got != durationErr.Error() {
+		t.Fatalf("expected the message to be unchanged, got %q", got)
+	}
+}
+
+func TestCreateSessions(t *testing.T) {
+	withTempDir(t, func(dir string) {
+		cwd, err := os.Getwd()
+		if err != nil {
+			t.Fatalf("Getwd: %v", err)
+		}
+		defer os.Chdir(cwd)
+		if err := os.Chdir(dir); err != nil {
+			t.Fatalf("Chdir: %v", err)
+		}
+		setClock(t, time.Date(2024, 3, 1, 12, 0, 0, 0, time.Local))
+		writeSession(Session{Name: "run", Mode: "counter", Elapsed: "95.0s"})
+
+		for _, bad := range [][]string{{"tea"}, {"=3m"}, {"tea=soon"}, {"tea=0"}, {"bad name=3m"}, {"tea=3m", "tea=5m"}} {
+			if _, err := parseCreateSpecs(bad); err == nil {
+				t.Errorf("parseCreateSpecs(%q): expected an error", bad)
+			}
+		}
+
+		sessions, err := parseCreateSpecs([]string{"tea=3m", "steep=5 min", "rest=10m"})
+		if err != nil {
+			t.Fatalf("parseCreateSpecs: %v", err)
+		}
+		if err := createSessions(sessions); err != nil {
+			t.Fatalf("createSessions: %v", err)
+		}
+		steep, err := loadSession("steep")
+		if err != nil {
+			t.Fatalf("loadSession: %v", err)
+		}
+		if !steep.Paused || steep.Mode != "timer" || steep.Remaining != "300.0s" || steep.Start != "2024-03-01:12-00-00" {
+			t.Fatalf("expected a paused 5m countdown, got %+v", steep)
+		}
+		if run, err := loadSession("run"); err != nil || run.Elapsed != "95.0s" {
+			t.Fatalf("expected other sessions to be kept, got %+v (%v)", run, err)
+		}
+
+		// One existing name rejects the whole batch
+		sessions, _ = parseCreateSpecs([]string{"eggs=6m", "tea=2m"})
+		if err := createSessions(sessions); err == nil || !strings.Contains(err.Error(), "tea") {
+			t.Fatalf("expected an error for the existing tea session, got %v", err)
+		}
+		if _, err := loadSession("eggs"); err == nil {
+			t.Fatal("expected nothing to be written from a rejected batch")
+		}
+
+		// Another process holding sessions.json is waited for
+		unlock := lockSessions(filepath.Join(dir, "sessions.json"))
+		done := make(chan error)
+		go func() {
+			sessions, _ := parseCreateSpecs([]string{"eggs=6m"})
+			done <- createSessions(sessions)
+		}()
+		select {
+		case <-done:
+			t.Fatal("expected createSessions to wait for the lock")
+		case <-time.After(100 * time.Millisecond):
+		}
+		unlock()
+		if err := <-done; err != nil {
+			t.Fatalf("createSessions: %v", err)
+		}
+	})
+}
+
+func TestResetSession(t *testing.T) {
+	withTempDir(t, func(dir string) {
+		cwd, err := os.Getwd()
+		if err != nil {
+			t.Fatalf("Getwd: %v", err)
+		}
+		defer os.Chdir(cwd)
+		if err := os.Chdir(dir); err != nil {
+			t.Fatalf("Chdir: %v", err)
+		}
+		setClock(t, time.Date(2024, 3, 1, 12, 0, 0, 0, time.UTC))
+
+		overrides := &SessionOverrides{WarningThreshold: 2 * time.Minute}
+		writeSession(Session{Name: "run", Mode: "counter", Elapsed: "95.0s", Goal: "600.0s", Overrides: overrides})
+		writeSession(Session{Name: "tea", Mode: "timer", Elapsed: "10.0s"})
+
+		session, err := resetSession("run")
+		if err != nil {
+			t.Fatalf("resetSession: %v", err)
+		}
+		stored, err := loadSession("run")
+		if err != nil {
+			t.Fatalf("loadSession: %v", err)
+		}
+		if stored.Elapsed != "0s" || !stored.Reset || stored.Start != "2024-03-01:12-00-00" {
+			t.Fatalf("expected zeroed counter flagged for reset, got %+v", stored)
+		}
+		if stored.Goal != "600.0s" || stored.Overrides == nil || session.Name != "run" {
+			t.Fatalf("expected name, goal and overrides to be kept, got %+v", stored)
+		}
+		if _, err := resetSession("tea"); err == nil {
+			t.Fatalf("expected error resetting a countdown")
+		}
+
+		entries, _ := os.ReadDir(".")
+		if len(entries) != 1 {
+			t.Fatalf("expected only sessions.json after atomic writes, got %d entries", len(entries))
+		}
+	})
+}
+
+func TestTodayTotal(t *testing.T) {
+	day := time.Date(2024, 3, 10, 18, 0, 0, 0, time.Local)
+	sessions := map[string]Session{
+		"default": {Current: "2024-03-10:09-25-00", Elapsed: "1500.0s", Finished: true},
+		"tea":     {Current: "2024-03-10:11-03-00", Elapsed: "180.0s", Finished: true},
+		"running": {Current: "2024-03-10:12-00-00", Elapsed: "600.0s"},
+		"old":     {Current: "2024-03-09:23-59-00", Elapsed: "900.0s", Finished: true},
+		"again":   {Current: "2024-03-10:08-00-00", Elapsed: "60.0s", Finished: true},
+	}
+	if got, want := todayTotal(sessions, "again", day), 1680*time.Second; got != want {
+		t.Fatalf("todayTotal = %v, want %v", got, want)
+	}
+	if got := todayTotal(nil, "default", day); got != 0 {
+		t.Fatalf("todayTotal of no sessions = %v, want 0", got)
+	}
+}
+
+func TestOvertimeColor(t *testing.T) {
+	defer resetGlobals()
+	cases := map[time.Duration]string{
+		0:                 redColor,
+		90 * time.Second:  redColor,
+		2 * time.Minute:   overtimeColors[1],
+		299 * time.Second: overtimeColors[1],
+		5 * time.Minute:   overtimeColors[2],
+		time.Hour:         overtimeColors[2],
+	}
+	for over, want := range cases {
+		if got := overtimeColor(over); got != want {
+			t.Errorf("overtimeColor(%v) = %q, want %q", over, got, want)
+		}
+	}
+	// More points than colors stay at the most intense one
+	overtimeEscalation = []time.Duration{time.Second, 2 * time.Second, 3 * time.Second}
+	if got := overtimeColor(time.Minute); got != overtimeColors[len(overtimeColors)-1] {
+		t.Fatalf("expected the strongest color, got %q", got)
+	}
+	for name, style := range glyphStyles {
+		if _, ok := style.glyphs['-']; !ok {
+			t.Errorf("%s glyphs have no '-' for overtime", name)
+		}
+	}
+}
+
+func TestLoadConfigOvertime(t *testing.T) {
+	defer resetGlobals()
+	withTempDir(t, func(dir string) {
+		configDir := filepath.Join(dir, "go-timer")
+		if err := os.MkdirAll(configDir, 0755); err != nil {
+			t.Fatalf("MkdirAll: %v", err)
+		}
+		config := `{"overtime": true, "overtimeBeep": "30s", "overtimeEscalation": ["10m", "1m", "1m"]}`
+		if err := os.WriteFile(filepath.Join(configDir, "config.json"), []byte(config), 0644); err != nil {
+			t.Fatalf("write config: %v", err)
+		}
+		t.Setenv("XDG_CONFIG_HOME", dir)
+		systemConfigDir = filepath.Join(dir, "missing")
+
+		if out := captureWarnings(t, loadConfig); out != "" {
+			t.Fatalf("unexpected warnings %q", out)
+		}
+		if !overtimeEnabled || overtimeBeep != 30*time.Second {
+			t.Fatalf("overtime settings not applied: %v, %v", overtimeEnabled, overtimeBeep)
+		}
+		if len(overtimeEscalation) != 2 || overtimeEscalation[0] != time.Minute || overtimeEscalation[1] != 10*time.Minute {
+			t.Fatalf("expected escalation [1m 10m], got %v", overtimeEscalation)
+		}
+	})
+}
+
+func TestStartPromptText(t *testing.T) {
+	if got := startPromptText("Press any key to start ({duration})", 5*time.Minute); got != "Press any key to start (5 minutes)" {
+		t.Fatalf("unexpected countdown prompt %q", got)
+	}
+	if got := startPromptText("Ready? {duration}", 0); got != "Ready? stopwatch" {
+		t.Fatalf("unexpected stopwatch prompt %q", got)
+	}
+	if got := startPromptText("Hands up when done", time.Minute); got != "Hands up when done" {
+		t.Fatalf("expected text without a placeholder unchanged, got %q", got)
+	}
+}
+
+func TestAccessibleDue(t *testing.T) {
+	cases := []struct {
+		sec       int64
+		isCounter bool
+		want      bool
+	}{
+		{600, false, true},
+		{599, false, false},
+		{11, false, false},
+		{10, false, true},
+		{0, false, true},
+		{-60, false, true}, // Overtime minute
+		{-5, false, false},
+		{120, true, true},
+		{5, true, false},
+	}
+	for _, c := range cases {
+		if got := accessibleDue(c.sec, c.isCounter); got != c.want {
+			t.Errorf("accessibleDue(%d, %v) = %v, want %v", c.sec, c.isCounter, got, c.want)
+		}
+	}
+}
+
+func TestSpokenTime(t *testing.T) {
+	cases := []struct {
+		display   time.Duration
+		isCounter bool
+		goal      time.Duration
+		toGoal    bool
+		want      string
+	}{
+		{330 * time.Second, false, 0, false, "5 minutes and 30 seconds remaining"},
+		{-90 * time.Second, false, 0, false, "1 minute and 30 seconds over"},
+		{time.Minute, true, 0, false, "1 minute elapsed"},
+		{10 * time.Minute, true, 30 * time.Minute, true, "20 minutes to the goal"},
+		{31 * time.Minute, true, 30 * time.Minute, false, "1 minute past the goal"},
+	}
+	for _, c := range cases {
+		if got := spokenTime(c.display, c.isCounter, c.goal, c.toGoal); got != c.want {
+			t.Errorf("spokenTime(%v) = %q, want %q", c.display, got, c.want)
+		}
+	}
+}
+
+func TestHumanizeDuration(t *testing.T) {
+	cases := map[time.Duration]string{
+		0:                           "0 seconds",
+		400 * time.Millisecond:      "0 seconds",
+		time.Second:                 "1 second",
+		25 * time.Minute:            "25 minutes",
+		90 * time.Second:            "1 minute and 30 seconds",
+		time.Hour:                   "1 hour",
+		2*time.Hour + 5*time.Second: "2 hours and 5 seconds",
+		time.Hour + 2*time.Minute + 1500*time.Millisecond: "1 hour, 2 minutes and 2 seconds",
+	}
+	for d, want := range cases {
+		if got := humanizeDuration(d); got != want {
+			t.Errorf("humanizeDuration(%v) = %q, want %q", d, got, want)
+		}
+	}
+}
+
+func TestParseCheckpoints(t *testing.T) {
+	config := []time.Duration{5 * time.Minute, 30 * time.Second}
+	got, err := parseCheckpoints(config, strings.Split("1m, 10m,5m,,90", ","))
+	if err != nil {
+		t.Fatalf("parseCheckpoints: %v", err)
+	}
+	want := []time.Duration{10 * time.Minute, 5 * time.Minute, 90 * time.Second, time.Minute, 30 * time.Second}
+	if len(got) != len(want) {
+		t.Fatalf("parseCheckpoints = %v, want %v", got, want)
+	}
+	for i := range want {
+		if got[i] != want[i] {
+			t.Fatalf("parseCheckpoints = %v, want %v", got, want)
+		}
+	}
+	if config[0] != 5*time.Minute || config[1] != 30*time.Second {
+		t.Fatalf("existing checkpoints must not be modified, got %v", config)
+	}
+	if _, err := parseCheckpoints(nil, []string{"5m", "soon"}); err == nil {
+		t.Fatalf("expected error for invalid checkpoint")
+	}
+}
+
+func TestIsNegativeDuration(t *testing.T) {
+	cases := map[string]bool{
+		"-5s":     true,
+		"-10":     true,
+		"-1.5m":   true,
+		"-i":      false,
+		"-5x":     false,
+		"-":       false,
+		"5s":      false,
+		"-inline": false,
+	}
+	for arg, want := range cases {
+		if got := isNegativeDuration(arg); got != want {
+			t.Errorf("isNegativeDuration(%q) = %v, want %v", arg, got, want)
+		}
+	}
+}
+
+func TestParseTrailingArgs(t *testing.T) {
+	t.Cleanup(func() {
+		cliFlags.Set("group", "")
+		cliFlags.Set("format", "human")
+	})
+
+	// Flags with a value after a subcommand, spaced or with "="
+	positional, relative, err := parseTrailingArgs([]string{"watch", "-group", "work"}, "")
+	if err != nil || strings.Join(positional, " ") != "watch" || relative != "" || *groupName != "work" {
+		t.Fatalf("watch -group work: got %q %q %v, group %q", positional, relative, err, *groupName)
+	}
+	positional, _, err = parseTrailingArgs([]string{"parse", "-format=json", "5m"}, "")
+	if err != nil || strings.Join(positional, " ") != "parse 5m" || *outputFormat != "json" {
+		t.Fatalf("parse -format=json 5m: got %q %v, format %q", positional, err, *outputFormat)
+	}
+	cliFlags.Set("format", "human")
+	positional, _, err = parseTrailingArgs([]string{"parse", "-format", "json", "5m"}, "")
+	if err != nil || strings.Join(positional, " ") != "parse 5m" || *outputFormat != "json" {
+		t.Fatalf("parse -format json 5m: got %q %v, format %q", positional, err, *outputFormat)
+	}
+
+	// A relative adjustment only fills an empty slot
+	if _, relative, _ := parseTrailingArgs([]string{"+5m"}, ""); relative != "+5m" {
+		t.Fatalf("expected +5m as the relative arg, got %q", relative)
+	}
+	if positional, relative, _ := parseTrailingArgs([]string{"+5m"}, "-2m"); relative != "-2m" || len(positional) != 1 {
+		t.Fatalf("expected -2m kept and +5m positional, got %q %q", positional, relative)
+	}
+
+	// A missing value or an unknown flag shows the usage
+	if _, _, err := parseTrailingArgs([]string{"watch", "-group"}, ""); !errors.Is(err, errShowUsage) {
+		t.Fatalf("expected usage for a missing value, got %v", err)
+	}
+	if _, _, err := parseTrailingArgs([]string{"watch", "-bogus"}, ""); !errors.Is(err, errShowUsage) {
+		t.Fatalf("expected usage for an unknown flag, got %v", err)
+	}
+}
+
+func TestFinishedRestoreAction(t *testing.T) {
+	defer resetGlobals()
+	finished := Session{Mode: "timer", Finished: true}
+	cases := []struct {
+		mode    string
+		session Session
+		restart bool
+		skip    string
+	}{
+		{"restart", finished, true, ""},
+		{"show", finished, false, ""},
+		{"ignore", finished, false, `timer: session "default" has already finished; nothing to restore`},
+		{"ignore", Session{Name: "tea", Finished: true}, false, `timer: session "tea" has already finished; nothing to restore`},
+		{"ignore", Session{Name: "tea"}, false, ""}, // Unfinished sessions restore as usual
+		{"restart", Session{Name: "tea"}, false, ""},
+	}
+	for _, c := range cases {
+		restoreFinished = c.mode
+		restart, skip := finishedRestoreAction(c.session)
+		if restart != c.restart || skip != c.skip {
+			t.Errorf("%s %+v: got %v %q, want %v %q", c.mode, c.session, restart, skip, c.restart, c.skip)
+		}
+	}
+}
+
+func TestFormatSummary(t *testing.T) {
+	start := time.Date(2024, 1, 1, 9, 0, 0, 0, time.UTC)
+	summary := TimerSummary{
+		Start:    start,
+		End:      start.Add(5*time.Minute + 1500*time.Millisecond),
+		Duration: 5*time.Minute + 1500*time.Millisecond,
+		Mode:     "timer",
+		Finished: true,
+		Name:     "tea",
+	}
+	cases := map[string]string{
+		"seconds": "301.5\n",
+		"clock":   "00:05:02\n",
+		"json":    `{"name":"tea","start":"2024-01-01T09:00:00Z","end":"2024-01-01T09:05:01Z","duration":301.5,"mode":"timer","finished":true}` + "\n",
+	}
+	for format, want := range cases {
+		if got, err := formatSummary(summary, format); err != nil || got != want {
+			t.Errorf("formatSummary(%s) = %q, %v; want %q", format, got, err, want)
+		}
+	}
+	human, err := formatSummary(summary, "human")
+	if err != nil || !strings.HasPrefix(human, "Name: tea\nStart: 2024-01-01 09:00:00\n") || !strings.HasSuffix(human, "Finished: true\n") {
+		t.Fatalf("unexpected human summary %q, %v", human, err)
+	}
+	if _, err := formatSummary(summary, "xml"); err == nil {
+		t.Fatalf("expected error for unknown format")
+	}
+}
+
+func TestFormatSummaryPercent(t *testing.T) {
+	defer resetGlobals()
+	start := time.Date(2024, 1, 1, 9, 0, 0, 0, time.UTC)
+	summary := TimerSummary{
+		Start:    start,
+		End:      start.Add(100 * time.Second),
+		Duration: 100 * time.Second,
+		Mode:     "timer",
+		Total:    3 * time.Minute,
+	}
+	got, err := formatSummary(summary, "json")
+	if err != nil || !strings.Contains(got, `"finished":false,"percent":44.4}`) {
+		t.Fatalf("expected 80s of 180s left as 44.4, got %q, %v", got, err)
+	}
+
+	summary.Duration = 3 * time.Minute
+	summary.Finished = true
+	if got, _ := formatSummary(summary, "json"); !strings.Contains(got, `"percent":0}`) {
+		t.Fatalf("expected a finished timer at 0, got %q", got)
+	}
+
+	// Rounding follows the display: 59.4s of 60s left shows as 00:59 when flooring
+	summary = TimerSummary{Mode: "timer", Duration: 600 * time.Millisecond, Total: time.Minute}
+	rounding = "floor"
+	if p, ok := remainingPercent(summary); !ok || p != 98.3 {
+		t.Fatalf("floor: expected 98.3, got %v, %v", p, ok)
+	}
+	rounding = "ceil"
+	if p, ok := remainingPercent(summary); !ok || p != 100 {
+		t.Fatalf("ceil: expected 100, got %v, %v", p, ok)
+	}
+
+	counter := TimerSummary{Start: start, End: start, Mode: "counter", Duration: time.Minute}
+	if got, _ := formatSummary(counter, "json"); strings.Contains(got, "percent") {
+		t.Fatalf("counter summary should omit percent, got %q", got)
+	}
+}
+
+func TestResolveNoArg(t *testing.T) {
+	defer resetGlobals()
+	if d, ok := resolveNoArg(); d != 0 || !ok {
+		t.Fatalf("counter: expected 0, true; got %v, %v", d, ok)
+	}
+	noArgBehavior = "usage"
+	if _, ok := resolveNoArg(); ok {
+		t.Fatalf("usage: expected ok=false")
+	}
+	noArgBehavior = "default"
+	defaultDuration = 25 * time.Minute
+	if d, ok := resolveNoArg(); d != 25*time.Minute || !ok {
+		t.Fatalf("default: expected 25m, true; got %v, %v", d, ok)
+	}
+	defaultDuration = 0
+	var d time.Duration
+	out := captureWarnings(t, func() { d, _ = resolveNoArg() })
+	if d != 0 || !strings.Contains(out, "defaultDuration") {
+		t.Fatalf("default without duration: expected counter with warning, got %v (%q)", d, out)
+	}
+
+	// Only a positive defaultDuration is taken from the config
+	for _, value := range []string{`"0s"`, `"-5m"`} {
+		if warnings := loadTestConfig(t, `{"defaultDuration": `+value+`}`); !strings.Contains(warnings, "defaultDuration must be positive") {
+			t.Fatalf("%s: expected a warning, got %q", value, warnings)
+		}
+	}
+	if warnings := loadTestConfig(t, `{"defaultDuration": "25m"}`); warnings != "" || defaultDuration != 25*time.Minute {
+		t.Fatalf("expected 25m without warnings, got %v (%q)", defaultDuration, warnings)
+	}
+}
+
+func TestVersionString(t *testing.T) {
+	origVersion, origCommit, origDate := version, commit, buildDate
+	defer func() { version, commit, buildDate = origVersion, origCommit, origDate }()
+	version, commit, buildDate = "v1.2.3", "abc1234", "2024-06-01T00:00:00Z"
+	want := "timer version v1.2.3 (commit abc1234, built 2024-06-01T00:00:00Z)"
+	if got := versionString(); got != want {
+		t.Fatalf("expected %q, got %q", want, got)
+	}
+}
+
+func TestFormatDuration(t *testing.T) {
+	if got := formatDuration(0); got != "0s" {
+		t.Fatalf("expected 0s, got %s", got)
+	}
+	if got := formatDuration(1500 * time.Millisecond); got != "1.5s" {
+		t.Fatalf("expected 1.5s, got %s", got)
+	}
+	if got := formatDuration(10 * time.Second); got != "10.0s" {
+		t.Fatalf("expected 10.0s, got %s", got)
+	}
+	if got := formatDuration(250 * time.Millisecond); got != "0.3s" {
+		t.Fatalf("expected 250ms to round half up to 0.3s, got %s", got)
+	}
+	if got := formatDuration(20 * time.Millisecond); got != "0.0s" {
+		t.Fatalf("expected 0.0s for a tiny non-zero duration, got %s", got)
+	}
+}
+
+func TestFormatDurationMatchesSprintf(t *testing.T) {
+	reference := func(d time.Duration) string {
+		if d == 0 {
+			return "0s"
+		}
+		return fmt.Sprintf("%.1fs", d.Round(100*time.Millisecond).Seconds())
+	}
+	durations := []time.Duration{
+		time.Nanosecond, 49 * time.Millisecond, 50 * time.Millisecond, 950 * time.Millisecond,
+		59*time.Minute + 59950*time.Millisecond, 100 * time.Hour, 1<<53 - 1,
+		-40 * time.Millisecond, -250 * time.Millisecond, -90 * time.Second,
+	}
+	for ms := time.Duration(0); ms < 100*time.Second; ms += 7 * time.Millisecond {
+		durations = append(durations, ms)
+	}
+	for _, d := range durations {
+		got, want := formatDuration(d), reference(d)
+		if got != want {
+			t.Fatalf("formatDuration(%v) = %q, want %q", d, got, want)
+		}
+		if back := parseFormattedDuration(got); back != d.Round(100*time.Millisecond) {
+			t.Fatalf("parseFormattedDuration(%q) = %v, want %v", got, back, d.Round(100*time.Millisecond))
+		}
+	}
+}
+
+func TestStoredDuration(t *testing.T) {
+	defer resetGlobals()
+	if got := storedDuration(10400 * time.Millisecond); got != "10.4s" {
+		t.Fatalf("expected full precision 10.4s, got %s", got)
+	}
+	roundElapsed = true
+	if got := storedDuration(10400 * time.Millisecond); got != "10.0s" {
+		t.Fatalf("expected rounded 10.0s, got %s", got)
+	}
+	if got := storedDuration(10600 * time.Millisecond); got != "11.0s" {
+		t.Fatalf("expected rounded 11.0s, got %s", got)
+	}
+}
+
+func TestParseFormattedDuration(t *testing.T) {
+	if got := parseFormattedDuration("0s"); got != 0 {
+		t.Fatalf("expected 0, got %v", got)
+	}
+	if got := parseFormattedDuration("1.5s"); got != 1500*time.Millisecond {
+		t.Fatalf("expected 1.5s, got %v", got)
+	}
+	if got := parseFormattedDuration("10.0s"); got != 10*time.Second {
+		t.Fatalf("expected 10s, got %v", got)
+	}
+	if got := parseFormattedDuration("bad"); got != 0 {
+		t.Fatalf("expected 0 on bad input, got %v", got)
+	}
+	cases := map[string]time.Duration{
+		"0.0s":  0,
+		"0.25s": 250 * time.Millisecond,
+		"0.3s":  300 * time.Millisecond,
+		"1m30s": 90 * time.Second,
+		"":      0,
+		"1.5":   0,
+	}
+	for in, want := range cases {
+		if got := parseFormattedDuration(in); got != want {
+			t.Errorf("parseFormattedDuration(%q) = %v, want %v", in, got, want)
+		}
+	}
+	// Every formatted value round-trips to within the 0.1s precision
+	for _, d := range []time.Duration{0, 20 * time.Millisecond, 250 * time.Millisecond, 1500 * time.Millisecond, 90 * time.Minute} {
+		if got := parseFormattedDuration(formatDuration(d)); got != d.Round(100*time.Millisecond) {
+			t.Errorf("round trip of %v gave %v", d, got)
+		}
+	}
+}
+
+func withTempDir(t *testing.T, fn func(dir string)) {
+	t.Helper()
+	dir, err := os.MkdirTemp("", "timer-test-*")
+	if err != nil {
+		t.Fatalf("MkdirTemp: %v", err)
+	}
+	defer os.RemoveAll(dir)
+	fn(dir)
+}
+
+// chdirTemp runs the rest of the test from a fresh temporary directory, so it
+// gets a sessions.json of its own, and returns that directory
+func chdirTemp(tb testing.TB) string {
+	tb.Helper()
+	dir := tb.TempDir()
+	tb.Chdir(dir)
+	return dir
+}
+
+func TestLoadSession(t *testing.T) {
+	withTempDir(t, func(dir string) {
+		cwd, err := os.Getwd()
+		if err != nil {
+			t.Fatalf("Getwd: %v", err)
+		}
+		defer os.Chdir(cwd)
+		if err := os.Chdir(dir); err != nil {
+			t.Fatalf("Chdir: %v", err)
+		}
+
+		sessions := map[string]Session{
+			"default": {
+				Start:    "2024-01-01:00-00-00",
+				Current:  "2024-01-01:00-00-05",
+				Elapsed:  "5.0s",
+				Paused:   false,
+				Mode:     "timer",
+				Name:     "default",
+				Finished: false,
+				Inline:   false,
+			},
+			"named": {
+				Start:    "2024-01-01:00-00-00",
+				Current:  "2024-01-01:00-00-10",
+				Elapsed:  "10.0s",
+				Paused:   true,
+				Mode:     "counter",
+				Name:     "named",
+				Finished: false,
+				Inline:   true,
+			},
+		}
+		data, err := json.Marshal(sessions)
+		if err != nil {
+			t.Fatalf("marshal: %v", err)
+		}
+		if err := os.WriteFile("sessions.json", data, 0644); err != nil {
+			t.Fatalf("write sessions.json: %v", err)
+		}
+
+		if _, err := loadSession(""); err != nil {
+			t.Fatalf("loadSession default: %v", err)
+		}
+		if _, err := loadSession("named"); err != nil {
+			t.Fatalf("loadSession named: %v", err)
+		}
+		if _, err := loadSession("missing"); err == nil {
+			t.Fatalf("expected error for missing session")
+		} else if exitCode(err) != exitNotFound {
+			t.Fatalf("expected a missing session to exit %d, got %d", exitNotFound, exitCode(err))
+		}
+	})
+}
+
+func TestReadSessionsNormalizes(t *testing.T) {
+	data := []byte(`{
+  "work": {"mode": "counter", "name": "Work", "elapsed": "5.0s"},
+  "default": {"mode": "timer", "name": ""},
+  "tea": {"mode": "timer", "name": "tea", "elapsed": "1.0s"},
+  "tea": {"mode": "timer", "name": "tea", "elapsed": "2.0s"}
+}`)
+	sessions, fixes, err := readSessions(data)
+	if err != nil {
+		t.Fatalf("readSessions: %v", err)
+	}
+	if sessions["work"].Name != "work" {
+		t.Fatalf("expected key to win over mismatched name, got %q", sessions["work"].Name)
+	}
+	if sessions["default"].Name != "" {
+		t.Fatalf("default session with empty name should be left alone, got %q", sessions["default"].Name)
+	}
+	if sessions["tea"].Elapsed != "2.0s" {
+		t.Fatalf("expected last duplicate to win, got %q", sessions["tea"].Elapsed)
+	}
+	if len(fixes) != 2 || !strings.Contains(fixes[0], `"tea"`) || !strings.Contains(fixes[1], `"Work"`) {
+		t.Fatalf("expected duplicate and name fixes to be reported, got %q", fixes)
+	}
+
+	withTempDir(t, func(dir string) {
+		cwd, err := os.Getwd()
+		if err != nil {
+			t.Fatalf("Getwd: %v", err)
+		}
+		defer os.Chdir(cwd)
+		if err := os.Chdir(dir); err != nil {
+			t.Fatalf("Chdir: %v", err)
+		}
+		if err := os.WriteFile("sessions.json", data, 0644); err != nil {
+			t.Fatalf("write sessions: %v", err)
+		}
+		var session Session
+		out := captureWarnings(t, func() { session, err = loadSession("work") })
+		if err != nil || session.Name != "work" || !strings.Contains(out, `using "work"`) {
+			t.Fatalf("loadSession should correct and report the name: %+v, %v, %q", session, err, out)
+		}
+		if out := captureWarnings(t, func() { session, err = loadSession("work") }); out != "" {
+			t.Fatalf("expected the corrected store to be written back, got %q", out)
+		}
+
+		// The correction waits while another writer holds sessions.json
+		if err := os.WriteFile("sessions.json", data, 0644); err != nil {
+			t.Fatalf("write sessions: %v", err)
+		}
+		unlock := lockSessions(filepath.Join(dir, "sessions.json"))
+		done := make(chan struct{})
+		go func() {
+			captureWarnings(t, func() { loadSession("work") })
+			close(done)
+		}()
+		select {
+		case <-done:
+			t.Fatalf("expected loadSession to wait for the lock")
+		case <-time.After(100 * time.Millisecond):
+		}
+		unlock()
+		<-done
+	})
+}
+
+func TestSessionOverrides(t *testing.T) {
+	withTempDir(t, func(dir string) {
+		cwd, err := os.Getwd()
+		if err != nil {
+			t.Fatalf("Getwd: %v", err)
+		}
+		defer os.Chdir(cwd)
+		if err := os.Chdir(dir); err != nil {
+			t.Fatalf("Chdir: %v", err)
+		}
+
+		data := `{
+  "meeting": {"elapsed": "0s", "mode": "timer", "name": "meeting", "overrides": {"warningThreshold": 600000000000}},
+  "plain": {"elapsed": "0s", "mode": "timer", "name": "plain"}
+}`
+		if err := os.WriteFile("sessions.json", []byte(data), 0644); err != nil {
+			t.Fatalf("write sessions.json: %v", err)
+		}
+
+		meeting, err := loadSession("meeting")
+		if err != nil {
+			t.Fatalf("loadSession meeting: %v", err)
+		}
+		if got := meeting.Overrides.warningThreshold(); got != 10*time.Minute {
+			t.Fatalf("expected override 10m, got %v", got)
+		}
+		plain, err := loadSession("plain")
+		if err != nil {
+			t.Fatalf("loadSession plain: %v", err)
+		}
+		if plain.Overrides != nil {
+			t.Fatalf("expected no overrides, got %+v", plain.Overrides)
+		}
+		if got := plain.Overrides.warningThreshold(); got != warningThreshold {
+			t.Fatalf("expected global threshold %v, got %v", warningThreshold, got)
+		}
+
+		// Outside the global key's 1m-1h range the global threshold applies
+		for _, d := range []time.Duration{time.Second, 2 * time.Hour} {
+			overrides := &SessionOverrides{WarningThreshold: d}
+			if got := overrides.warningThreshold(); got != warningThreshold {
+				t.Fatalf("%v: expected global threshold %v, got %v", d, warningThreshold, got)
+			}
+		}
+	})
+}
+
+func TestNormalizeSessionName(t *testing.T) {
+	valid := map[string]string{
+		"":             "",
+		"work":         "work",
+		"  deep_work ": "deep_work",
+		"Team-2":       "Team-2",
+	}
+	for in, want := range valid {
+		got, err := normalizeSessionName(in)
+		if err != nil || got != want {
+			t.Fatalf("%q: expected %q, got %q (%v)", in, want, got, err)
+		}
+	}
+	for _, in := range []string{"a/b", "line\nbreak", "two words", "café"} {
+		if _, err := normalizeSessionName(in); err == nil {
+			t.Fatalf("%q: expected error", in)
+		}
+	}
+	if _, err := loadSession("../etc"); err == nil {
+		t.Fatalf("loadSession: expected error for invalid name")
+	}
+}
+
+func TestModeColor(t *testing.T) {
+	defer resetGlobals()
+	if got := modeColor("timer"); got != "" {
+		t.Fatalf("expected no color by default, got %q", got)
+	}
+	defaultColor = colorCodes["white"]
+	counterColor = colorCodes["blue"]
+	if got := modeColor("timer"); got != defaultColor {
+		t.Fatalf("timer without own color should use default, got %q", got)
+	}
+	if got := modeColor("counter"); got != counterColor {
+		t.Fatalf("counter should use its own color, got %q", got)
+	}
+}
+
+func TestAnsiColor(t *testing.T) {
+	const truecolor, colors256, colors16 = 1 << 24, 256, 16
+	cases := []struct {
+		spec  string
+		depth int
+		want  string
+	}{
+		{"Cyan", colors16, colorCodes["cyan"]},
+		{"208", colors256, "\033[38;5;208m"},
+		{"208", colors16, "\033[33m"}, // Orange becomes yellow
+		{"9", colors16, "\033[91m"},
+		{"#ff8700", truecolor, "\033[38;2;255;135;0m"},
+		{"#ff8700", colors256, "\033[38;5;208m"},
+		{"#FF8700", colors16, "\033[33m"},
+		{"#000000", colors16, "\033[30m"},
+		{"#808080", colors256, "\033[38;5;244m"},
+	}
+	for _, c := range cases {
+		if got, ok := ansiColor(c.spec, c.depth); !ok || got != c.want {
+			t.Errorf("ansiColor(%q, %d) = %q, %v; want %q", c.spec, c.depth, got, ok, c.want)
+		}
+	}
+	for _, spec := range []string{"orange", "256", "-1", "#ff87", "#gg0000", "ff8700"} {
+		if got, ok := ansiColor(spec, truecolor); ok {
+			t.Errorf("ansiColor(%q) = %q; want invalid", spec, got)
+		}
+	}
+
+	t.Setenv("COLORTERM", "truecolor")
+	if colorDepth() != truecolor {
+		t.Errorf("COLORTERM=truecolor should allow truecolor")
+	}
+	t.Setenv("COLORTERM", "")
+	t.Setenv("TERM", "xterm-256color")
+	if colorDepth() != colors256 {
+		t.Errorf("xterm-256color should allow 256 colors")
+	}
+	t.Setenv("TERM", "xterm")
+	if colorDepth() != colors16 {
+		t.Errorf("plain xterm should get 16 colors")
+	}
+}
+
+func TestSeparator(t *testing.T) {
+	defer resetGlobals()
+	if got := formatHMS(65 * time.Second); got != "01:05" {
+		t.Fatalf("expected 01:05, got %s", got)
+	}
+	separator = '.'
+	applyGlyphStyle()
+	if separator != '.' {
+		t.Fatalf("'.' has a glyph and should be kept")
+	}
+	if got := formatHMS(time.Hour + 65*time.Second); got != "01.01.05" {
+		t.Fatalf("expected 01.01.05, got %s", got)
+	}
+	separator = '/'
+	out := captureWarnings(t, applyGlyphStyle)
+	if separator != ':' || out == "" {
+		t.Fatalf("unrenderable separator should fall back to ':' with a warning")
+	}
+}
+
+func TestTrimLeadingZeros(t *testing.T) {
+	defer resetGlobals()
+	trimLeadingZeros = true
+	cases := map[time.Duration]string{
+		0:                               "0:00",
+		5 * time.Second:                 "0:05",
+		3*time.Minute + 5*time.Second:   "3:05",
+		9*time.Minute + 59*time.Second:  "9:59",
+		10 * time.Minute:                "10:00",
+		59*time.Minute + 59*time.Second: "59:59",
+		time.Hour:                       "1:00:00",
+		time.Hour + 2*time.Minute + 3*time.Second: "1:02:03",
+		10 * time.Hour: "10:00:00",
+	}
+	for d, want := range cases {
+		if got := formatHMS(d); got != want {
+			t.Errorf("formatHMS(%v) = %q, want %q", d, got, want)
+		}
+	}
+	// Narrower strings stay centered: the big text width follows the string length
+	narrow := renderBigTime("3:05", 200, 50)
+	trimLeadingZeros = false
+	wide := renderBigTime(formatHMS(3*time.Minute+5*time.Second), 200, 50)
+	if len(strings.Split(narrow, "\n")[0]) >= len(strings.Split(wide, "\n")[0]) {
+		t.Fatalf("expected trimmed time to render narrower")
+	}
+}
+
+func TestRenderBigTimeNarrowTerminal(t *testing.T) {
+	defer resetGlobals()
+	glyphs, glyphWidth, glyphHeight = blockGlyphs, 6, 5
+	glyphSpacing = 5
+
+	// 5 glyphs with 5-column gaps need 50 columns; on 40 the gaps shrink to 1 (34 wide)
+	lines := strings.Split(renderBigTime("10:00", 40, 24), "\n")
+	if len(lines) != glyphHeight {
+		t.Fatalf("expected big digits on a 40-column terminal, got %q", lines)
+	}
+	for _, line := range lines {
+		if n := utf8.RuneCountInString(line); n != 34 {
+			t.Fatalf("expected rows 34 columns wide, got %d: %q", n, line)
+		}
+	}
+	// A wide clock that overflows even without gaps falls back to plain text
+	if got := renderBigTime("10:00:00", 40, 24); got != "10:00:00" {
+		t.Fatalf("expected plain text for 10:00:00 on 40 columns, got %q", got)
+	}
+	// Roomy terminals keep the configured spacing
+	if lines := strings.Split(renderBigTime("10:00", 80, 24), "\n"); utf8.RuneCountInString(lines[0]) != 50 {
+		t.Fatalf("expected the configured spacing on 80 columns, got %q", lines[0])
+	}
+}
+
+func TestDisplayRounding(t *testing.T) {
+	defer resetGlobals()
+	cases := []struct {
+		mode string
+		d    time.Duration
+		want string
+	}{
+		{"nearest", 400 * time.Millisecond, "00:00"},
+		{"nearest", 1500 * time.Millisecond, "00:02"},
+		{"ceil", time.Nanosecond, "00:01"},
+		{"ceil", 999 * time.Millisecond, "00:01"},
+		{"ceil", time.Second, "00:01"},
+		{"ceil", time.Second + time.Millisecond, "00:02"},
+		{"ceil", 0, "00:00"},
+		{"floor", 1999 * time.Millisecond, "00:01"},
+	}
+	for _, c := range cases {
+		rounding = c.mode
+		if got := formatHMS(c.d); got != c.want {
+			t.Errorf("%s formatHMS(%v) = %q, want %q", c.mode, c.d, got, c.want)
+		}
+	}
+
+	// With ceil a countdown shows 1 right up to the zero crossing, never 0
+	rounding = "ceil"
+	duration := 5 * time.Second
+	for elapsed := 4 * time.Second; elapsed < duration; elapsed += 50 * time.Millisecond {
+		if got := formatHMS(duration - elapsed); got != "00:01" {
+			t.Fatalf("remaining %v shown as %q, want 00:01", duration-elapsed, got)
+		}
+	}
+	if formatHMS(duration-(duration-time.Nanosecond)) != "00:01" {
+		t.Fatalf("last frame before finish should show 00:01")
+	}
+}
+
+func TestInlineLine(t *testing.T) {
+	if got := inlineLine("", "03:00", ""); got != "\r03:00"+clearToEOL {
+		t.Fatalf("unexpected plain inline line %q", got)
+	}
+	if got := inlineLine("tea", "00:59", redColor); got != "\rtea "+redColor+"00:59"+resetStyle+clearToEOL {
+		t.Fatalf("unexpected named inline line %q", got)
+	}
+	// Shrinking from h:mm:ss to mm:ss relies on the clear, not on padding
+	if got := inlineLine("", "59:59", ""); !strings.HasSuffix(got, "59:59"+clearToEOL) {
+		t.Fatalf("expected the rest of the line to be cleared, got %q", got)
+	}
+}
+
+func TestWithCaptions(t *testing.T) {
+	if got := withCaptions("BIG", "", ""); got != "BIG" {
+		t.Fatalf("expected no captions, got %q", got)
+	}
+	if got := withCaptions("BIG", "quiz", "Question 3: show your work"); got != "quiz\n\nBIG\n\nQuestion 3: show your work" {
+		t.Fatalf("unexpected captions %q", got)
+	}
+	if got := withCaptions("BIG", "", "steeping"); got != "BIG\n\nsteeping" {
+		t.Fatalf("unexpected description-only caption %q", got)
+	}
+}
+
+func TestFitInline(t *testing.T) {
+	defer resetGlobals()
+	cases := []struct {
+		name, timeStr string
+		width         int
+		wantName      string
+		wantTime      string
+	}{
+		{"pomodoro", "25:00", 0, "pomodoro", "25:00"},
+		{"pomodoro", "25:00", 14, "pomodoro", "25:00"},
+		{"pomodoro", "25:00", 10, "pom…", "25:00"},
+		{"pomodoro", "25:00", 7, "", "25:00"},
+		{"pomodoro", "01:02:03", 6, "", "1h02"},
+		{"", "05:30", 4, "", "5m"},
+		{"", "00:42", 3, "", "42s"},
+		{"", "+12:00", 4, "", "+12m"},
+	}
+	for _, c := range cases {
+		name, timeStr := fitInline(c.name, c.timeStr, c.width)
+		if name != c.wantName || timeStr != c.wantTime {
+			t.Errorf("fitInline(%q, %q, %d) = %q, %q; want %q, %q", c.name, c.timeStr, c.width, name, timeStr, c.wantName, c.wantTime)
+		}
+	}
+
+	inlineWidth = 30
+	var none *SessionOverrides
+	if got := none.inlineWidth(); got != 30 {
+		t.Fatalf("expected the global inlineWidth, got %d", got)
+	}
+	if got := (&SessionOverrides{InlineWidth: 12}).inlineWidth(); got != 12 {
+		t.Fatalf("expected the session inlineWidth, got %d", got)
+	}
+}
+
+func TestCornerLine(t *testing.T) {
+	got := cornerLine(" 05:00 ", 7, 80)
+	want := saveCursor + moveCursor(1, 1) + clearLine + moveCursor(1, 74) + " 05:00 " + loadCursor
+	if got != want {
+		t.Fatalf("cornerLine = %q, want %q", got, want)
+	}
+	// Wider than the terminal: clamp to the first column
+	if got := cornerLine("xxxxx", 5, 3); !strings.Contains(got, moveCursor(1, 1)+"xxxxx") {
+		t.Fatalf("expected overlay clamped to column 1, got %q", got)
+	}
+}
+
+func TestGlyphStylesDimensions(t *testing.T) {
+	for name, style := range glyphStyles {
+		for ch, rows := range style.glyphs {
+			if len(rows) != style.height {
+				t.Fatalf("%s %q: expected %d rows, got %d", name, ch, style.height, len(rows))
+			}
+			for _, row := range rows {
+				if n := len([]rune(row)); n != style.width {
+					t.Fatalf("%s %q: expected width %d, got %d in %q", name, ch, style.width, n, row)
+				}
+			}
+		}
+	}
+}
+
+func TestApplyGlyphStyle(t *testing.T) {
+	defer resetGlobals()
+	t.Setenv("LC_ALL", "")
+	t.Setenv("LC_CTYPE", "")
+
+	t.Setenv("LANG", "en_US.UTF-8")
+	applyGlyphStyle()
+	if glyphWidth != 6 || glyphHeight != 5 || glyphs['0'][0] != blockGlyphs['0'][0] {
+		t.Fatalf("auto on UTF-8 should select block glyphs")
+	}
+
+	t.Setenv("LANG", "C")
+	applyGlyphStyle()
+	if glyphWidth != 8 || glyphHeight != 7 || glyphs['0'][0] != asciiGlyphs['0'][0] {
+		t.Fatalf("auto without UTF-8 should select ascii glyphs")
+	}
+
+	glyphStyleName = "dots"
+	applyGlyphStyle()
+	if glyphs['0'][0] != dotGlyphs['0'][0] {
+		t.Fatalf("forced style should be used regardless of locale")
+	}
+
+	// Sizes from the config give way to the style, with a warning
+	warnings := loadTestConfig(t, `{"glyphStyle": "ascii", "glyphWidth": 12, "glyphHeight": 9}`)
+	if !strings.Contains(warnings, "glyphWidth is replaced") || !strings.Contains(warnings, "glyphHeight is replaced") {
+		t.Fatalf("expected warnings for the glyph sizes, got %q", warnings)
+	}
+	applyGlyphStyle()
+	if glyphWidth != 8 || glyphHeight != 7 {
+		t.Fatalf("expected the ascii style's 8x7, got %dx%d", glyphWidth, glyphHeight)
+	}
+}
+
+func TestCycleGlyphStyle(t *testing.T) {
+	defer resetGlobals()
+	glyphStyleName = "block"
+	applyGlyphStyle()
+	for _, want := range []string{"dots", "ascii", "block"} {
+		if got := cycleGlyphStyle(); got != want {
+			t.Fatalf("expected %s next, got %s", want, got)
+		}
+		style := glyphStyles[want]
+		if glyphWidth != style.width || glyphHeight != style.height || glyphs['0'][0] != style.glyphs['0'][0] {
+			t.Fatalf("%s: glyphs and dimensions not switched", want)
+		}
+	}
+	if useGlyphStyle("fancy") || activeGlyphStyle != "block" {
+		t.Fatalf("unknown style should be rejected and keep block, got %s", activeGlyphStyle)
+	}
+}
+
+func TestGetTickerInterval(t *testing.T) {
+	defer resetGlobals()
+	for _, tiers := range []struct{ fast, medium time.Duration }{
+		{time.Minute, 10 * time.Minute}, // Defaults
+		{5 * time.Minute, 30 * time.Minute},
+	} {
+		fastBelow, mediumBelow = tiers.fast, tiers.medium
+		if got := getTickerInterval(0); got != tickIntervalFast {
+			t.Fatalf("counter expected fast, got %v", got)
+		}
+		cases := []struct {
+			duration time.Duration
+			want     time.Duration
+		}{
+			{time.Second, tickIntervalFast},
+			{tiers.fast - time.Second, tickIntervalFast},
+			{tiers.fast, tickIntervalMedium},
+			{tiers.medium - time.Second, tickIntervalMedium},
+			{tiers.medium, tickIntervalSlow},
+			{2 * tiers.medium, tickIntervalSlow},
+		}
+		for _, c := range cases {
+			if got := getTickerInterval(c.duration); got != c.want {
+				t.Errorf("tiers %v/%v: getTickerInterval(%v) = %v, want %v", tiers.fast, tiers.medium, c.duration, got, c.want)
+			}
+		}
+	}
+}
+
+func TestLoadConfigTickTiers(t *testing.T) {
+	defer resetGlobals()
+	if out := loadTestConfig(t, `{"fastBelow": "5m"}`); out != "" {
+		t.Fatalf("unexpected warnings %q", out)
+	}
+	if fastBelow != 5*time.Minute || mediumBelow != 10*time.Minute {
+		t.Fatalf("expected tiers 5m/10m, got %v/%v", fastBelow, mediumBelow)
+	}
+
+	resetGlobals()
+	out := loadTestConfig(t, `{"fastBelow": "20m", "mediumBelow": "15m"}`)
+	if !strings.Contains(out, "fastBelow") || fastBelow != time.Minute || mediumBelow != 10*time.Minute {
+		t.Fatalf("expected out-of-order tiers to fall back with a warning, got %v/%v (%q)", fastBelow, mediumBelow, out)
+	}
+}
+
+// setClock replaces the package clock with a manually advanced one
+func setClock(t *testing.T, start time.Time) func(time.Duration) {
+	t.Helper()
+	current := start
+	orig := now
+	now = func() time.Time { return current }
+	t.Cleanup(func() { now = orig })
+	return func(d time.Duration) { current = current.Add(d) }
+}
+
+// frameRecorder collects what the timer draws, one frame per write
+type frameRecorder struct {
+	mu     sync.Mutex
+	frames []string
+}
+
+func (r *frameRecorder) Write(p []byte) (int, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.frames = append(r.frames, string(p))
+	return len(p), nil
+}
+
+// waitFor returns the first frame containing text, failing after a few seconds
+func (r *frameRecorder) waitFor(t *testing.T, text string) string {
+	t.Helper()
+	for deadline := time.Now().Add(3 * time.Second); time.Now().Before(deadline); time.Sleep(10 * time.Millisecond) {
+		r.mu.Lock()
+		for _, frame := range r.frames {
+			if strings.Contains(frame, text) {
+				r.mu.Unlock()
+				return frame
+			}
+		}
+		r.mu.Unlock()
+	}
+	t.Fatalf("no frame containing %q", text)
+	return ""
+}
+
+// waitAfter waits for a frame after the first n that contains text, and
+// returns the number of frames up to and including it
+func (r *frameRecorder) waitAfter(t *testing.T, n int, text string) int {
+	t.Helper()
+	for deadline := time.Now().Add(3 * time.Second); time.Now().Before(deadline); time.Sleep(10 * time.Millisecond) {
+		r.mu.Lock()
+		for i := n; i < len(r.frames); i++ {
+			if strings.Contains(r.frames[i], text) {
+				r.mu.Unlock()
+				return i + 1
+			}
+		}
+		r.mu.Unlock()
+	}
+	t.Fatalf("no frame containing %q after frame %d", text, n)
+	return 0
+}
+
+// captureFrames runs the timer without a terminal: frames go to the returned
+// recorder and keys are read from the channel
+func captureFrames(t *testing.T, keys chan []byte) *frameRecorder {
+	t.Helper()
+	recorder := &frameRecorder{}
+	origOut, origRaw, origRead := tuiOut, enterRawMode, readInput
+	tuiOut = recorder
+	enterRawMode = func() (func(), error) { return func() {}, nil }
+	readInput = func(buf []byte) (int, error) {
+		data, ok := <-keys
+		if !ok {
+			return 0, io.EOF
+		}
+		return copy(buf, data), nil
+	}
+	t.Cleanup(func() {
+		tuiOut, enterRawMode, readInput = origOut, origRaw, origRead
+		close(keys)
+	})
+	return recorder
+}
+
+func TestRunTimerFrames(t *testing.T) {
+	defer resetGlobals()
+	persistSessions = false
+	t.Setenv("NO_COLOR", "1")
+	keys := make(chan []byte)
+	frames := captureFrames(t, keys)
+
+	summaryCh := make(chan TimerSummary, 1)
+	done := make(chan error, 1)
+	go func() {
+		done <- runTimer(timerOptions{Name: "tea", InitialElapsed: 5 * time.Second}, summaryCh)
+	}()
+
+	// The inline stopwatch draws its name and the time carried over
+	frame := frames.waitFor(t, "00:05")
+	if !strings.Contains(frame, "tea") || strings.Contains(frame, "\033[3") {
+		t.Fatalf("expected an uncolored inline frame with the name, got %q", frame)
+	}
+
+	keys <- []byte("q")
+	frames.waitFor(t, "quitting...")
+	select {
+	case err := <-done:
+		if err != nil {
+			t.Fatalf("runTimer: %v", err)
+		}
+	case <-time.After(3 * time.Second):
+		t.Fatalf("timer did not quit")
+	}
+	if summary := <-summaryCh; summary.Mode != "counter" || summary.Duration < 5*time.Second {
+		t.Fatalf("unexpected summary %+v", summary)
+	}
+}
+
+func TestRunTimerNoMouse(t *testing.T) {
+	defer resetGlobals()
+	persistSessions = false
+	mouseEnabled = false
+	keys := make(chan []byte)
+	frames := captureFrames(t, keys)
+
+	summaryCh := make(chan TimerSummary, 1)
+	done := make(chan error, 1)
+	go func() {
+		done <- runTimer(timerOptions{Fullscreen: true}, summaryCh)
+	}()
+	frames.waitFor(t, clearScreen)
+
+	// A mouse report is dropped, not read as keys: the clock keeps running
+	// into the next second, when the screen is redrawn
+	frames.mu.Lock()
+	reported := len(frames.frames)
+	frames.mu.Unlock()
+	keys <- []byte("\033[<0;10;5M")
+	time.Sleep(1100 * time.Millisecond)
+	frames.mu.Lock()
+	redrawn := strings.Contains(strings.Join(frames.frames[reported:], ""), clearScreen)
+	frames.mu.Unlock()
+	if !redrawn {
+		t.Fatalf("no redraw after the mouse report")
+	}
+	keys <- []byte("q")
+	if err := <-done; err != nil {
+		t.Fatalf("runTimer: %v", err)
+	}
+	if summary := <-summaryCh; summary.Duration < time.Second {
+		t.Fatalf("expected the timer still running after the report, got %v", summary.Duration)
+	}
+	frames.mu.Lock()
+	output := strings.Join(frames.frames, "")
+	frames.mu.Unlock()
+	if strings.Contains(output, mouseOn) {
+		t.Fatalf("mouse reporting was turned on with mouse disabled")
+	}
+	if !strings.Contains(output, mouseOff) {
+		t.Fatalf("expected mouse reporting switched off on exit")
+	}
+}
+
+func TestRunTimerPauseReason(t *testing.T) {
+	defer resetGlobals()
+	chdirTemp(t)
+	keys := make(chan []byte)
+	frames := captureFrames(t, keys)
+
+	summaryCh := make(chan TimerSummary, 1)
+	done := make(chan error, 1)
+	go func() {
+		done <- runTimer(timerOptions{Name: "tea"}, summaryCh)
+	}()
+	frames.waitFor(t, "00:00")
+
+	// p pauses and asks for the reason, Enter labels the pause, Space resumes;
+	// each step saves the session in the background while the next one runs
+	keys <- []byte("p")
+	keys <- []byte("phone\r")
+	keys <- []byte(" ")
+	keys <- []byte("q")
+	if err := <-done; err != nil {
+		t.Fatalf("runTimer: %v", err)
+	}
+	<-summaryCh
+
+	session, err := loadSession("tea")
+	if err != nil {
+		t.Fatalf("loadSession: %v", err)
+	}
+	if len(session.Pauses) != 1 || session.Pauses[0].Reason != "phone" || session.Pauses[0].End == "" {
+		t.Fatalf("expected one closed pause for phone, got %+v", session.Pauses)
+	}
+}
+
+func TestRunTimerOvertimeSaved(t *testing.T) {
+	defer resetGlobals()
+	chdirTemp(t)
+	overtimeEnabled = true
+	keys := make(chan []byte)
+	frames := captureFrames(t, keys)
+
+	summaryCh := make(chan TimerSummary, 1)
+	done := make(chan error, 1)
+	go func() {
+		done <- runTimer(timerOptions{Name: "standup", Duration: 10 * time.Second, InitialElapsed: 12 * time.Second}, summaryCh)
+	}()
+	frames.waitFor(t, "-00:02")
+	keys <- []byte("q")
+	if err := <-done; err != nil {
+		t.Fatalf("runTimer: %v", err)
+	}
+	<-summaryCh
+
+	// Past zero the session counts as finished for -wait-for, the today
+	// total and restoreFinished, yet still reads as live in split views
+	session, err := loadSession("standup")
+	if err != nil {
+		t.Fatalf("loadSession: %v", err)
+	}
+	if !session.Finished || session.Overtime == "" {
+		t.Fatalf("expected a finished session with overtime, got %+v", session)
+	}
+	if got := todayTotal(map[string]Session{"standup": session}, "", now()); got < 12*time.Second {
+		t.Fatalf("expected the overtime run in the today total, got %v", got)
+	}
+	if !sessionLive(session, now()) {
+		t.Fatalf("expected a countdown in overtime to stay live")
+	}
+}
+
+func TestRunTimerIdlePause(t *testing.T) {
+	defer resetGlobals()
+	persistSessions = false
+	idleTimeout = time.Second
+	keys := make(chan []byte)
+	frames := captureFrames(t, keys)
+
+	summaryCh := make(chan TimerSummary, 1)
+	done := make(chan error, 1)
+	go func() {
+		done <- runTimer(timerOptions{Accessible: true}, summaryCh)
+	}()
+	frames.waitFor(t, "0 seconds")
+
+	// A focus report is not input: the idle time still runs from the start
+	time.Sleep(500 * time.Millisecond)
+	keys <- []byte("\033[O")
+	frames.waitFor(t, "paused")
+
+	// The first key only wakes the counter, the next one quits
+	keys <- []byte("x")
+	keys <- []byte("q")
+	if err := <-done; err != nil {
+		t.Fatalf("runTimer: %v", err)
+	}
+	if summary := <-summaryCh; summary.Duration >= 300*time.Millisecond {
+		t.Fatalf("expected the idle time rolled back to the last keypress, got %v", summary.Duration)
+	}
+}
+
+func TestRunTimerReviewOnQuit(t *testing.T) {
+	defer resetGlobals()
+	chdirTemp(t)
+	reviewOnQuit = true
+	keys := make(chan []byte)
+	frames := captureFrames(t, keys)
+
+	summaryCh := make(chan TimerSummary, 1)
+	done := make(chan error, 1)
+	go func() {
+		done <- runTimer(timerOptions{Name: "run", InitialElapsed: 5 * time.Second}, summaryCh)
+	}()
+	frames.waitFor(t, "00:05")
+
+	// q freezes the clock; it stays frozen however long the review takes
+	keys <- []byte("q")
+	frames.waitFor(t, "press any key to exit")
+	time.Sleep(1200 * time.Millisecond)
+	keys <- []byte("x")
+	if err := <-done; err != nil {
+		t.Fatalf("runTimer: %v", err)
+	}
+	summary := <-summaryCh
+	if summary.Duration < 5*time.Second || summary.Duration >= 6*time.Second {
+		t.Fatalf("expected the frozen time in the summary, got %v", summary.Duration)
+	}
+	session, err := loadSession("run")
+	if err != nil {
+		t.Fatalf("loadSession: %v", err)
+	}
+	if !session.Paused || session.Elapsed != storedDuration(summary.Duration) {
+		t.Fatalf("expected the frozen time saved as paused, got %+v", session)
+	}
+}
+
+func TestRunTimerConfirmQuit(t *testing.T) {
+	defer resetGlobals()
+	persistSessions = false
+	confirmQuit = true
+	keys := make(chan []byte)
+	frames := captureFrames(t, keys)
+
+	summaryCh := make(chan TimerSummary, 1)
+	done := make(chan error, 1)
+	go func() {
+		done <- runTimer(timerOptions{Name: "tea"}, summaryCh)
+	}()
+	frames.waitFor(t, "00:00")
+
+	// Anything but y cancels and the timer keeps running
+	keys <- []byte("q")
+	frames.waitFor(t, "quit? (y/n)")
+	keys <- []byte("n")
+	frames.waitFor(t, "00:02")
+	select {
+	case err := <-done:
+		t.Fatalf("expected n to cancel the quit, runTimer returned %v", err)
+	default:
+	}
+
+	// y confirms
+	keys <- []byte("q")
+	keys <- []byte("y")
+	frames.waitFor(t, "quitting...")
+	select {
+	case err := <-done:
+		if err != nil {
+			t.Fatalf("runTimer: %v", err)
+		}
+	case <-time.After(3 * time.Second):
+		t.Fatalf("timer did not quit")
+	}
+	if summary := <-summaryCh; summary.Duration < time.Second {
+		t.Fatalf("expected the time before the cancel kept, got %+v", summary)
+	}
+}
+
+func TestRunTimerWaitForStart(t *testing.T) {
+	defer resetGlobals()
+	persistSessions = false
+	keys := make(chan []byte)
+	frames := captureFrames(t, keys)
+
+	summaryCh := make(chan TimerSummary, 1)
+	done := make(chan error, 1)
+	go func() {
+		done <- runTimer(timerOptions{Name: "tea", WaitForStart: true}, summaryCh)
+	}()
+	frames.waitFor(t, "00:00")
+
+	// The clock holds until a key that isn't a command, e.g. not n
+	keys <- []byte("n")
+	time.Sleep(1600 * time.Millisecond)
+	frames.mu.Lock()
+	output := strings.Join(frames.frames, "")
+	frames.mu.Unlock()
+	if strings.Contains(output, "00:01") || strings.Contains(output, "00:02") {
+		t.Fatalf("expected the clock held at 00:00 before the first key, got %q", output)
+	}
+	keys <- []byte("x")
+	frames.waitFor(t, "00:01")
+
+	keys <- []byte("q")
+	if err := <-done; err != nil {
+		t.Fatalf("runTimer: %v", err)
+	}
+	if summary := <-summaryCh; summary.Duration < 500*time.Millisecond || summary.Duration >= 1600*time.Millisecond {
+		t.Fatalf("expected only the time since the first key counted, got %v", summary.Duration)
+	}
+}
+
+func TestRunTimerSetTitle(t *testing.T) {
+	defer resetGlobals()
+	persistSessions = false
+	setTitleEnabled = true
+	origTerminal := tuiIsTerminal
+	defer func() { tuiIsTerminal = origTerminal }()
+	tuiIsTerminal = func() bool { return true }
+	keys := make(chan []byte)
+	frames := captureFrames(t, keys)
+
+	summaryCh := make(chan TimerSummary, 1)
+	done := make(chan error, 1)
+	go func() {
+		done <- runTimer(timerOptions{Name: "tea", InitialElapsed: 5 * time.Second}, summaryCh)
+	}()
+
+	// The title follows the clock, and the terminal's own comes back on exit
+	frames.waitFor(t, windowTitle("00:05 tea"))
+	keys <- []byte("q")
+	if err := <-done; err != nil {
+		t.Fatalf("runTimer: %v", err)
+	}
+	<-summaryCh
+	frames.mu.Lock()
+	output := strings.Join(frames.frames, "")
+	frames.mu.Unlock()
+	push, title, pop := strings.Index(output, pushTitle), strings.LastIndex(output, "\033]0;"), strings.Index(output, popTitle)
+	if push < 0 || push > title || pop < title {
+		t.Fatalf("expected the title saved first and restored last, got %q", output)
+	}
+}
+
+func TestRunTimerContinue(t *testing.T) {
+	defer resetGlobals()
+	chdirTemp(t)
+	run := func(initialElapsed time.Duration, until string) {
+		t.Helper()
+		keys := make(chan []byte)
+		frames := captureFrames(t, keys)
+		summaryCh := make(chan TimerSummary, 1)
+		done := make(chan error, 1)
+		go func() {
+			done <- runTimer(timerOptions{Name: "work", InitialElapsed: initialElapsed}, summaryCh)
+		}()
+		frames.waitFor(t, until)
+		keys <- []byte("q")
+		if err := <-done; err != nil {
+			t.Fatalf("runTimer: %v", err)
+		}
+		<-summaryCh
+	}
+
+	// Each -continue run picks up the counter's stored total, as Main does
+	resume := func() time.Duration {
+		t.Helper()
+		session, err := takeSession("work")
+		if err != nil || session.Mode != "counter" {
+			t.Fatalf("expected a stored counter, got %+v, %v", session, err)
+		}
+		return restoredElapsed(session, now())
+	}
+	run(5*time.Second, "00:06")
+	elapsed := resume()
+	if elapsed < 5500*time.Millisecond {
+		t.Fatalf("expected the first run's total stored, got %v", elapsed)
+	}
+	run(elapsed, "00:07")
+	if total := resume(); total < 6500*time.Millisecond {
+		t.Fatalf("expected the second run added to the total, got %v", total)
+	}
+}
+
+func TestRunTimerNight(t *testing.T) {
+	defer resetGlobals()
+	chdirTemp(t)
+	keys := make(chan []byte)
+	frames := captureFrames(t, keys)
+
+	summaryCh := make(chan TimerSummary, 1)
+	done := make(chan error, 1)
+	go func() {
+		done <- runTimer(timerOptions{Name: "late", Duration: 10 * time.Minute}, summaryCh)
+	}()
+	if frame := frames.waitFor(t, "10:00"); strings.Contains(frame, faintStyle) {
+		t.Fatalf("expected a normal frame before n, got %q", frame)
+	}
+
+	// n dims the next frame and is saved with the session
+	frames.mu.Lock()
+	n := len(frames.frames)
+	frames.mu.Unlock()
+	keys <- []byte("n")
+	frames.waitAfter(t, n, faintStyle)
+	keys <- []byte("q")
+	if err := <-done; err != nil {
+		t.Fatalf("runTimer: %v", err)
+	}
+	<-summaryCh
+	if session, err := loadSession("late"); err != nil || !session.Night {
+		t.Fatalf("expected night mode saved, got %+v, %v", session, err)
+	}
+}
+
+func TestRunTimerShowToGoal(t *testing.T) {
+	defer resetGlobals()
+	chdirTemp(t)
+	keys := make(chan []byte)
+	frames := captureFrames(t, keys)
+
+	summaryCh := make(chan TimerSummary, 1)
+	done := make(chan error, 1)
+	go func() {
+		done <- runTimer(timerOptions{Name: "focus", Goal: 30 * time.Minute, InitialElapsed: 5 * time.Second}, summaryCh)
+	}()
+	frames.waitFor(t, "00:05")
+
+	// t switches a goal counter to the time left until the goal
+	keys <- []byte("t")
+	frames.waitFor(t, "29:5")
+	keys <- []byte("q")
+	if err := <-done; err != nil {
+		t.Fatalf("runTimer: %v", err)
+	}
+	<-summaryCh
+	if session, err := loadSession("focus"); err != nil || !session.ShowToGoal {
+		t.Fatalf("expected the time-left view saved, got %+v, %v", session, err)
+	}
+}
+
+func TestRunTimerFinishedResize(t *testing.T) {
+	defer resetGlobals()
+	persistSessions = false
+	finishExit = false
+	t.Setenv("PATH", t.TempDir()) // No notify-send
+	keys := make(chan []byte)
+	frames := captureFrames(t, keys)
+
+	summaryCh := make(chan TimerSummary, 1)
+	done := make(chan error, 1)
+	go func() {
+		done <- runTimer(timerOptions{Duration: time.Second, Fullscreen: true}, summaryCh)
+	}()
+	n := frames.waitAfter(t, 0, "finished! (press any key)")
+
+	// A resize while the finished display is held lays it out again
+	if err := syscall.Kill(os.Getpid(), syscall.SIGWINCH); err != nil {
+		t.Fatalf("Kill: %v", err)
+	}
+	n = frames.waitAfter(t, n, "finished! (press any key)")
+	frames.mu.Lock()
+	redraw := frames.frames[n-1]
+	frames.mu.Unlock()
+	if !strings.HasPrefix(redraw, clearScreen) {
+		t.Fatalf("expected the held display redrawn from a cleared screen, got %q", redraw)
+	}
+	keys <- []byte("x")
+	if err := <-done; err != nil {
+		t.Fatalf("runTimer: %v", err)
+	}
+	if summary := <-summaryCh; !summary.Finished {
+		t.Fatalf("expected a finished summary, got %+v", summary)
+	}
+}
+
+func TestRunTimerSignalPause(t *testing.T) {
+	defer resetGlobals()
+	chdirTemp(t)
+	keys := make(chan []byte)
+	frames := captureFrames(t, keys)
+	usr1 := func() {
+		t.Helper()
+		if err := syscall.Kill(os.Getpid(), syscall.SIGUSR1); err != nil {
+			t.Fatalf("Kill: %v", err)
+		}
+	}
+	saved := func(paused bool) Session {
+		t.Helper()
+		for deadline := time.Now().Add(3 * time.Second); time.Now().Before(deadline); time.Sleep(10 * time.Millisecond) {
+			if session, err := loadSession("usr"); err == nil && session.Paused == paused {
+				return session
+			}
+		}
+		t.Fatalf("expected the session saved with paused %v", paused)
+		return Session{}
+	}
+
+	// A waiting timer is started by the signal, then paused and resumed by it
+	summaryCh := make(chan TimerSummary, 1)
+	done := make(chan error, 1)
+	go func() {
+		done <- runTimer(timerOptions{Name: "usr", Accessible: true, WaitForStart: true}, summaryCh)
+	}()
+	n := frames.waitAfter(t, 0, "usr: paused")
+	usr1()
+	n = frames.waitAfter(t, n, "usr: 0")
+	saved(false)
+	usr1()
+	n = frames.waitAfter(t, n, "usr: paused")
+	if session := saved(true); len(session.Pauses) != 1 || session.Pauses[0].End != "" {
+		t.Fatalf("expected one open pause saved, got %+v", session.Pauses)
+	}
+	usr1()
+	frames.waitAfter(t, n, "usr: 0")
+	saved(false)
+	keys <- []byte("q")
+	if err := <-done; err != nil {
+		t.Fatalf("runTimer: %v", err)
+	}
+	<-summaryCh
+}
+
+func TestRunTimerSignalPauseReview(t *testing.T) {
+	defer resetGlobals()
+	chdirTemp(t)
+	reviewOnQuit = true
+	keys := make(chan []byte)
+	frames := captureFrames(t, keys)
+
+	summaryCh := make(chan TimerSummary, 1)
+	done := make(chan error, 1)
+	go func() {
+		done <- runTimer(timerOptions{Name: "usr"}, summaryCh)
+	}()
+	frames.waitFor(t, "00:00")
+	keys <- []byte("q")
+	frames.waitFor(t, "press any key to exit")
+
+	// The frozen review ignores the signal
+	if err := syscall.Kill(os.Getpid(), syscall.SIGUSR1); err != nil {
+		t.Fatalf("Kill: %v", err)
+	}
+	time.Sleep(100 * time.Millisecond)
+	keys <- []byte("x")
+	if err := <-done; err != nil {
+		t.Fatalf("runTimer: %v", err)
+	}
+	<-summaryCh
+	session, err := loadSession("usr")
+	if err != nil {
+		t.Fatalf("loadSession: %v", err)
+	}
+	if !session.Paused || len(session.Pauses) != 0 {
+		t.Fatalf("expected the review saved with no pause from the signal, got %+v", session)
+	}
+}
+
+func TestCrossedPausePoint(t *testing.T) {
+	points := []time.Duration{2 * time.Minute, time.Minute} // Sorted like parseCheckpoints
+	fired := []bool{false, false}
+	// Countdown of 5m: the 2m point is reached at 3m elapsed
+	if got := crossedPausePoint(points, fired, 2*time.Minute, 5*time.Minute); got != -1 {
+		t.Fatalf("nothing reached yet, got %d", got)
+	}
+	if got := crossedPausePoint(points, fired, 4*time.Minute+30*time.Second, 5*time.Minute); got != 0 {
+		t.Fatalf("expected the 2m point first when both are passed, got %d", got)
+	}
+	fired[0] = true
+	if got := crossedPausePoint(points, fired, 4*time.Minute+30*time.Second, 5*time.Minute); got != 1 {
+		t.Fatalf("expected the 1m point next, got %d", got)
+	}
+	// Stopwatch: points are elapsed time, the 1m one comes first
+	if got := crossedPausePoint(points, []bool{false, false}, 3*time.Minute, 0); got != 1 {
+		t.Fatalf("expected the 1m point first for a stopwatch, got %d", got)
+	}
+	if got := pendingPausePoints(points, fired); len(got) != 1 || got[0] != "60.0s" {
+		t.Fatalf("expected only 60.0s pending, got %q", got)
+	}
+}
+
+func TestRunTimerPauseAt(t *testing.T) {
+	defer resetGlobals()
+	persistSessions = false
+	keys := make(chan []byte)
+	frames := captureFrames(t, keys)
+
+	summaryCh := make(chan TimerSummary, 1)
+	done := make(chan error, 1)
+	go func() {
+		done <- runTimer(timerOptions{Duration: 10 * time.Second, PauseAt: []time.Duration{9900 * time.Millisecond}}, summaryCh)
+	}()
+	frames.waitFor(t, "press any key to continue")
+
+	// Stopped exactly where the point was reached, whatever the tick
+	keys <- []byte("q")
+	if err := <-done; err != nil {
+		t.Fatalf("runTimer: %v", err)
+	}
+	if summary := <-summaryCh; summary.Duration != 100*time.Millisecond {
+		t.Fatalf("expected the clock held at 100ms elapsed, got %v", summary.Duration)
+	}
+}
+
+func TestRunTimerPauseAtJumpedOver(t *testing.T) {
+	defer resetGlobals()
+	persistSessions = false
+	keys := make(chan []byte)
+	frames := captureFrames(t, keys)
+
+	summaryCh := make(chan TimerSummary, 1)
+	done := make(chan error, 1)
+	go func() {
+		done <- runTimer(timerOptions{PauseAt: []time.Duration{30 * time.Second}}, summaryCh)
+	}()
+	frames.waitFor(t, "00:00")
+
+	// Up adds a minute: the 30s point is skipped, not stopped at back in time
+	keys <- []byte("\033[A")
+	frames.waitFor(t, "01:01")
+	keys <- []byte("q")
+	if err := <-done; err != nil {
+		t.Fatalf("runTimer: %v", err)
+	}
+	if summary := <-summaryCh; summary.Duration < time.Minute {
+		t.Fatalf("expected the jump kept, got %v", summary.Duration)
+	}
+	frames.mu.Lock()
+	defer frames.mu.Unlock()
+	for _, frame := range frames.frames {
+		if strings.Contains(frame, "press any key to continue") {
+			t.Fatalf("paused at a point that was jumped over: %q", frame)
+		}
+	}
+}
+
+func TestNextSecondDelay(t *testing.T) {
+	defer resetGlobals()
+	ms := time.Millisecond
+	cases := []struct {
+		rounding          string
+		elapsed, duration time.Duration
+		want              time.Duration
+	}{
+		// Counter flips when elapsed reaches the next whole second
+		{"floor", 1300 * ms, 0, 700 * ms},
+		{"floor", 2 * time.Second, 0, time.Second},
+		// With nearest rounding the counter flips at the half second
+		{"nearest", 1300 * ms, 0, 200 * ms},
+		{"nearest", 1700 * ms, 0, 800 * ms},
+		// Countdown with ceil flips when remaining reaches a whole second
+		{"ceil", 1300 * ms, 10 * time.Second, 700 * ms},
+		{"ceil", 2 * time.Second, 10 * time.Second, time.Second},
+		// Never sleep past the finish
+		{"nearest", 9700 * ms, 10 * time.Second, 300 * ms},
+	}
+	for _, c := range cases {
+		rounding = c.rounding
+		if got := nextSecondDelay(c.elapsed, c.duration); got != c.want+alignMargin {
+			t.Errorf("%s nextSecondDelay(%v, %v) = %v, want %v", c.rounding, c.elapsed, c.duration, got, c.want+alignMargin)
+		}
+	}
+}
+
+func TestNextTickSound(t *testing.T) {
+	ms := time.Millisecond
+	cases := []struct {
+		elapsed, interval, want time.Duration
+	}{
+		{0, time.Second, time.Second},
+		{1300 * ms, time.Second, 2 * time.Second},
+		// Exactly on a beat: that one has just played
+		{2 * time.Second, time.Second, 3 * time.Second},
+		{4 * time.Second, 1500 * ms, 4500 * ms},
+		{-200 * ms, time.Second, time.Second},
+	}
+	for _, c := range cases {
+		if got := nextTickSound(c.elapsed, c.interval); got != c.want {
+			t.Errorf("nextTickSound(%v, %v) = %v, want %v", c.elapsed, c.interval, got, c.want)
+		}
+	}
+}
+
+func TestLoadConfigTickSound(t *testing.T) {
+	defer resetGlobals()
+	withTempDir(t, func(dir string) {
+		configDir := filepath.Join(dir, "go-timer")
+		if err := os.MkdirAll(configDir, 0755); err != nil {
+			t.Fatalf("MkdirAll: %v", err)
+		}
+		config := `{"tickSound": "tick.wav", "tickSoundInterval": "1ms"}`
+		if err := os.WriteFile(filepath.Join(configDir, "config.json"), []byte(config), 0644); err != nil {
+			t.Fatalf("write config: %v", err)
+		}
+		t.Setenv("XDG_CONFIG_HOME", dir)
+		systemConfigDir = filepath.Join(dir, "missing")
+
+		out := captureWarnings(t, loadConfig)
+		if !strings.Contains(out, "tickSoundInterval") {
+			t.Fatalf("expected a warning for the too short interval, got %q", out)
+		}
+		if tickSound != "tick.wav" || tickSoundInterval != time.Second {
+			t.Fatalf("expected tick.wav every 1s, got %q every %v", tickSound, tickSoundInterval)
+		}
+	})
+}
+
+func TestElapsedSince(t *testing.T) {
+	start := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	advance := setClock(t, start)
+
+	advance(30 * time.Second)
+	if got := elapsedSince(start, 0, false, time.Time{}); got != 30*time.Second {
+		t.Fatalf("running: expected 30s, got %v", got)
+	}
+
+	pauseStart := now()
+	advance(10 * time.Second)
+	if got := elapsedSince(start, 0, true, pauseStart); got != 30*time.Second {
+		t.Fatalf("paused: expected 30s, got %v", got)
+	}
+
+	advance(5 * time.Second)
+	if got := elapsedSince(start, 15*time.Second, false, time.Time{}); got != 30*time.Second {
+		t.Fatalf("resumed: expected 30s, got %v", got)
+	}
+}
+
+func TestSleepCompensation(t *testing.T) {
+	// Real clock readings, like the ticks: Add moves their monotonic and wall
+	// parts together, so these are gaps both clocks saw
+	prev := time.Now()
+	if prev.String() == prev.Round(0).String() {
+		t.Fatal("expected a monotonic clock reading")
+	}
+
+	// Regular tick - nothing to compensate
+	if e, p := sleepCompensation(prev, prev.Add(time.Second), time.Second, false); e != 0 || p != 0 {
+		t.Fatalf("regular tick: expected 0,0 got %v,%v", e, p)
+	}
+	// Long gap with sleep not counted - the gap becomes paused time
+	if e, p := sleepCompensation(prev, prev.Add(10*time.Minute), time.Second, false); e != 0 || p != 10*time.Minute-time.Second {
+		t.Fatalf("sleep excluded: expected 0,9m59s got %v,%v", e, p)
+	}
+	// Long gap with sleep counted and clocks agreeing - already elapsed, nothing to add
+	if e, p := sleepCompensation(prev, prev.Add(10*time.Minute), time.Second, true); e != 0 || p != 0 {
+		t.Fatalf("sleep counted: expected 0,0 got %v,%v", e, p)
+	}
+
+	// A suspend the monotonic clock saw only part of adds the rest from the wall clock
+	if e, p := gapCompensation(10*time.Minute, 15*time.Minute, time.Second, true); e != 5*time.Minute || p != 0 {
+		t.Fatalf("sleep partly missed: expected 5m,0 got %v,%v", e, p)
+	}
+	// A wall-clock step between regular ticks (NTP, a manual change) is not a suspend
+	for _, countSleep := range []bool{true, false} {
+		if e, p := gapCompensation(time.Second, 10*time.Minute, time.Second, countSleep); e != 0 || p != 0 {
+			t.Fatalf("clock step (countSleep %v): expected 0,0 got %v,%v", countSleep, e, p)
+		}
+		if e, p := gapCompensation(time.Second, -10*time.Minute, time.Second, countSleep); e != 0 || p != 0 {
+			t.Fatalf("clock set back (countSleep %v): expected 0,0 got %v,%v", countSleep, e, p)
+		}
+	}
+}
+
+func TestParseInput(t *testing.T) {
+	if b, ok := parseInput(nil); ok || b != 0 {
+		t.Fatalf("empty: expected 0,false got %d,%v", b, ok)
+	}
+	if b, ok := parseInput([]byte{'a'}); !ok || b != 'a' {
+		t.Fatalf("single: expected a,true got %d,%v", b, ok)
+	}
+	if b, ok := parseInput([]byte{0x1b}); ok || b != 0 {
+		t.Fatalf("esc single: expected 0,false got %d,%v", b, ok)
+	}
+	if b, ok := parseInput([]byte{0x1b, '['}); ok || b != 0 {
+		t.Fatalf("incomplete esc seq: expected 0,false got %d,%v", b, ok)
+	}
+	if b, ok := parseInput([]byte{0x1b, '[', 'M', 'x', 'y', 'M'}); !ok || b != 0 {
+		t.Fatalf("mouse seq: expected 0,true got %d,%v", b, ok)
+	}
+}
+
+func TestParseInputArrowKeys(t *testing.T) {
+	cases := map[string]byte{
+		"\033[A": keyUp,
+		"\033[B": keyDown,
+		"\033[C": keyRight,
+		"\033[D": keyLeft,
+		"\033OA": keyUp,
+	}
+	for in, want := range cases {
+		if b, ok := parseInput([]byte(in)); !ok || b != want {
+			t.Fatalf("%q: expected %d,true got %d,%v", in, want, b, ok)
+		}
+	}
+	if b, ok := parseInput([]byte{0xc3}); !ok || b != 0 {
+		t.Fatalf("non-ascii: expected 0,true got %d,%v", b, ok)
+	}
+	if got := timeAdjustment(keyUp); got != time.Minute {
+		t.Fatalf("up: expected 1m, got %v", got)
+	}
+	if got := timeAdjustment(keyLeft); got != -10*time.Second {
+		t.Fatalf("left: expected -10s, got %v", got)
+	}
+	if got := timeAdjustment('a'); got != 0 {
+		t.Fatalf("other key: expected 0, got %v", got)
+	}
+}
+
+func TestParseInputFocusEvents(t *testing.T) {
+	if b, ok := parseInput([]byte("\033[I")); !ok || b != keyFocusIn {
+		t.Fatalf("focus in: expected %d,true got %d,%v", keyFocusIn, b, ok)
+	}
+	if b, ok := parseInput([]byte("\033[O")); !ok || b != keyFocusOut {
+		t.Fatalf("focus out: expected %d,true got %d,%v", keyFocusOut, b, ok)
+	}
+	// SS3 sequences are keypad keys, not focus reports
+	if b, _ := parseInput([]byte("\033OI")); b == keyFocusIn {
+		t.Fatalf("\\033OI must not be reported as focus in")
+	}
+}
+
+func TestInputDecoderSplitSequences(t *testing.T) {
+	var d inputDecoder
+	// X10 mouse click whose button byte (0x20) would otherwise read as Space
+	if keys := d.feed([]byte{0x1b, '[', 'M'}); len(keys) != 0 {
+		t.Fatalf("first half of mouse report: expected no keys, got %q", keys)
+	}
+	if d.loneEscape() {
+		t.Fatalf("a partial sequence is not a lone ESC")
+	}
+	if keys := d.feed([]byte{0x20, '!', '!', 'q'}); string(keys) != "q" {
+		t.Fatalf("rest of mouse report then q: expected \"q\", got %q", keys)
+	}
+
+	// SGR mouse report split mid-parameters
+	if keys := d.feed([]byte("\033[<0;1")); len(keys) != 0 {
+		t.Fatalf("first half of SGR mouse report: expected no keys, got %q", keys)
+	}
+	if keys := d.feed([]byte("0;5M ")); string(keys) != " " {
+		t.Fatalf("rest of SGR mouse report then Space: expected \" \", got %q", keys)
+	}
+
+	// Arrow key split right after ESC, and a modified arrow that is ignored
+	if keys := d.feed([]byte{0x1b}); len(keys) != 0 || !d.loneEscape() {
+		t.Fatalf("lone ESC: expected it pending, got keys %q", keys)
+	}
+	if keys := d.feed([]byte("[A\033[1;5Ap")); string(keys) != string([]byte{keyUp, 'p'}) {
+		t.Fatalf("split arrow then ctrl+arrow then p: got %q", keys)
+	}
+
+	d.feed([]byte{0x1b})
+	d.reset()
+	if keys := d.feed([]byte("q")); string(keys) != "q" {
+		t.Fatalf("after reset: expected \"q\", got %q", keys)
+	}
+}
+
+func TestLineEditor(t *testing.T) {
+	var e lineEditor
+	feed := func(keys string) editResult {
+		result := editContinue
+		for i := 0; i < len(keys); i++ {
+			result = e.feed(keys[i])
+		}
+		return result
+	}
+
+	t.Run("escape cancels", func(t *testing.T) {
+		e.start()
+		if feed("10m") != editContinue || e.text() != "10m" {
+			t.Fatalf("expected typed text to accumulate, got %q", e.text())
+		}
+		if e.feed(0x1b) != editCancel || e.active {
+			t.Fatalf("expected Escape to cancel and leave the sub-mode")
+		}
+		e.start()
+		if e.text() != "" {
+			t.Fatalf("a new edit should start empty, got %q", e.text())
+		}
+	})
+	t.Run("enter commits", func(t *testing.T) {
+		e.start()
+		feed("25x")
+		e.feed(0x7f) // Backspace
+		if e.feed('\r') != editCommit || e.active || e.text() != "25" {
+			t.Fatalf("expected Enter to commit %q, got %q (active %v)", "25", e.text(), e.active)
+		}
+	})
+	t.Run("ignores control and arrow keys", func(t *testing.T) {
+		e.start()
+		feed("5")
+		e.feed(keyUp)
+		e.feed(0x01)
+		if e.text() != "5" || !e.active {
+			t.Fatalf("expected only printable keys to be kept, got %q", e.text())
+		}
+	})
+}
+
+func TestParseInputBracketedPaste(t *testing.T) {
+	paste := []byte("\033[200~q m\033[201~")
+	for i := 1; i < len(paste); i++ {
+		if b, ok := parseInput(paste[:i]); ok {
+			t.Fatalf("partial paste %q: expected 0,false got %d,%v", paste[:i], b, ok)
+		}
+	}
+	if b, ok := parseInput(paste); !ok || b != 0 {
+		t.Fatalf("paste: expected 0,true got %d,%v", b, ok)
+	}
+}
+
+func resetGlobals() {
+	tickIntervalFast = 100 * time.Millisecond
+	tickIntervalMedium = 500 * time.Millisecond
+	tickIntervalSlow = 1 * time.Second
+	warningThreshold = 5 * time.Minute
+	warningPercent = 0
+	fastBelow, mediumBelow = time.Minute, 10*time.Minute
+	inlineWidth = 0
+	nightMode = false
+	startPrompt = "Press any key to start ({duration})"
+	glyphWidth = 8
+	glyphHeight = 7
+	glyphSpacing = 1
+	keyBufferSize = 10
+	defaultTermWidth = 80
+	defaultTermHeight = 24
+	restoreEnabled = false
+	autoSaveInterval = 0
+	heartbeatEnabled = false
+	systemConfigDir = "/etc"
+	roundElapsed = false
+	confirmQuit = false
+	mouseEnabled = true
+	reviewOnQuit = false
+	setTitleEnabled = false
+	defaultColor = ""
+	timerColor = ""
+	counterColor = ""
+	glyphStyleName = "auto"
+	glyphs = dotGlyphs
+	activeGlyphStyle = ""
+	saveGlyphStyle = false
+	countDuringSleep = true
+	separator = ':'
+	syslogEnabled = false
+	influxMeasurement = "timer"
+	influxTags = nil
+	persistSessions = true
+	rounding = "nearest"
+	trimLeadingZeros = false
+	tuiOutput = "stdout"
+	alignToSecond = false
+	notifyCheck = true
+	startSound, warningSound, finishSound = "", "", ""
+	tickSound, tickSoundInterval = "", time.Second
+	snoozeDuration = 0
+	speakEnabled, speakCommand = false, ""
+	restoreFinished = "restart"
+	noArgBehavior = "counter"
+	defaultDuration = 0
+	configProfile = ""
+	checkpoints = nil
+	pauseOnFocusLoss = false
+	showTodayTotal = false
+	finishExit = true
+	idleTimeout = 0
+	useAltScreen = true
+	friendlyFinish = false
+	overtimeEnabled = false
+	restoreDowntime = "freeze"
+	overtimeBeep = 0
+	overtimeEscalation = []time.Duration{2 * time.Minute, 5 * time.Minute}
+}
+
+func TestLoadConfigValid(t *testing.T) {
+	defer resetGlobals()
+	withTempDir(t, func(dir string) {
+		configDir := filepath.Join(dir, "go-timer")
+		if err := os.MkdirAll(configDir, 0755); err != nil {
+			t.Fatalf("MkdirAll: %v", err)
+		}
+		cfg := Config{
+			TickIntervalFast:   200 * time.Millisecond,
+			TickIntervalMedium: 600 * time.Millisecond,
+			TickIntervalSlow:   1500 * time.Millisecond,
+			WarningThreshold:   10 * time.Minute,
+			GlyphWidth:         10,
+			GlyphHeight:        9,
+			GlyphSpacing:       2,
+			KeyBufferSize:      20,
+			DefaultTermWidth:   100,
+			DefaultTermHeight:  40,
+			Restore:            true,
+			AutoSaveInterval:   5 * time.Second,
+			Heartbeat:          true,
+			ConfirmQuit:        true,
+			ReviewOnQuit:       true,
+			SetTitle:           true,
+			Color:              "white",
+			TimerColor:         "Red",
+			CounterColor:       "cyan",
+			IdleTimeout:        2 * time.Minute,
+		}
+		b, err := json.Marshal(cfg)
+		if err != nil {
+			t.Fatalf("marshal cfg: %v", err)
+		}
+		if err := os.WriteFile(filepath.Join(configDir, "config.json"), b, 0644); err != nil {
+			t.Fatalf("write config: %v", err)
+		}
+
+		origUserConfigDir := os.Getenv("XDG_CONFIG_HOME")
+		defer os.Setenv("XDG_CONFIG_HOME", origUserConfigDir)
+		if runtime.GOOS != "windows" {
+			os.Setenv("XDG_CONFIG_HOME", dir)
+		}
+		systemConfigDir = filepath.Join(dir, "missing")
+
+		loadConfig()
+
+		if tickIntervalFast != cfg.TickIntervalFast {
+			t.Fatalf("tickIntervalFast not updated")
+		}
+		if tickIntervalMedium != cfg.TickIntervalMedium {
+			t.Fatalf("tickIntervalMedium not updated")
+		}
+		if tickIntervalSlow != cfg.TickIntervalSlow && cfg.TickIntervalSlow <= 5*time.Second {
+			t.Fatalf("tickIntervalSlow not updated")
+		}
+		if warningThreshold != cfg.WarningThreshold {
+			t.Fatalf("warningThreshold not updated")
+		}
+		if glyphWidth != cfg.GlyphWidth || glyphHeight != cfg.GlyphHeight || glyphSpacing != cfg.GlyphSpacing {
+			t.Fatalf("glyph config not updated")
+		}
+		if keyBufferSize != cfg.KeyBufferSize {
+			t.Fatalf("keyBufferSize not updated")
+		}
+		if defaultTermWidth != cfg.DefaultTermWidth || defaultTermHeight != cfg.DefaultTermHeight {
+			t.Fatalf("term size not updated")
+		}
+		if !restoreEnabled {
+			t.Fatalf("restoreEnabled not set")
+		}
+		if autoSaveInterval != cfg.AutoSaveInterval {
+			t.Fatalf("autoSaveInterval not updated")
+		}
+		if !heartbeatEnabled {
+			t.Fatalf("heartbeatEnabled not set")
+		}
+		if !confirmQuit {
+			t.Fatalf("confirmQuit not set")
+		}
+		if !reviewOnQuit {
+			t.Fatalf("reviewOnQuit not set")
+		}
+		if !setTitleEnabled {
+			t.Fatalf("setTitleEnabled not set")
+		}
+		if defaultColor != colorCodes["white"] || timerColor != colorCodes["red"] || counterColor != colorCodes["cyan"] {
+			t.Fatalf("colors not updated")
+		}
+		if idleTimeout != cfg.IdleTimeout {
+			t.Fatalf("idleTimeout not updated")
+		}
+	})
+}
+
+func TestLoadConfigInvalid(t *testing.T) {
+	defer resetGlobals()
+	withTempDir(t, func(dir string) {
+		configDir := filepath.Join(dir, "go-timer")
+		if err := os.MkdirAll(configDir, 0755); err != nil {
+			t.Fatalf("MkdirAll: %v", err)
+		}
+		cfg := Config{
+			TickIntervalFast:   1 * time.Millisecond,
+			TickIntervalMedium: 2 * time.Second,
+			TickIntervalSlow:   10 * time.Second,
+			WarningThreshold:   10 * time.Second,
+			GlyphWidth:         -1,
+			GlyphHeight:        0,
+			GlyphSpacing:       10,
+			KeyBufferSize:      1000,
+			DefaultTermWidth:   0,
+			DefaultTermHeight:  0,
+		}
+		b, err := json.Marshal(cfg)
+		if err != nil {
+			t.Fatalf("marshal cfg: %v", err)
+		}
+		if err := os.WriteFile(filepath.Join(configDir, "config.json"), b, 0644); err != nil {
+			t.Fatalf("write config: %v", err)
+		}
+
+		origUserConfigDir := os.Getenv("XDG_CONFIG_HOME")
+		defer os.Setenv("XDG_CONFIG_HOME", origUserConfigDir)
+		if runtime.GOOS != "windows" {
+			os.Setenv("XDG_CONFIG_HOME", dir)
+		}
+		systemConfigDir = filepath.Join(dir, "missing")
+
+		out := captureWarnings(t, loadConfig)
+		if !strings.Contains(out, "tickIntervalFast") || !strings.Contains(out, "tickIntervalMedium") || !strings.Contains(out, "tickIntervalSlow") {
+			t.Fatalf("expected warnings for out-of-range tick intervals, got %q", out)
+		}
+
+		if tickIntervalFast != 100*time.Millisecond || tickIntervalMedium != 500*time.Millisecond || tickIntervalSlow != 1*time.Second {
+			t.Fatalf("invalid durations should not override defaults")
+		}
+		if warningThreshold != 5*time.Minute {
+			t.Fatalf("invalid warning threshold should not override default")
+		}
+		if glyphWidth != 8 || glyphHeight != 7 || glyphSpacing != 1 {
+			t.Fatalf("invalid glyph config should not override defaults")
+		}
+		if keyBufferSize != 10 || defaultTermWidth != 80 || defaultTermHeight != 24 {
+			t.Fatalf("invalid other config should not override defaults")
+		}
+	})
+}
+
+func TestLoadConfigTickIntervals(t *testing.T) {
+	defer resetGlobals()
+	withTempDir(t, func(dir string) {
+		configDir := filepath.Join(dir, "go-timer")
+		if err := os.MkdirAll(configDir, 0755); err != nil {
+			t.Fatalf("MkdirAll: %v", err)
+		}
+		data := `{"tickIntervalFast": "50ms", "tickIntervalMedium": 10000000, "tickIntervalSlow": "-1s", "warningThreshold": "2m"}`
+		if err := os.WriteFile(filepath.Join(configDir, "config.json"), []byte(data), 0644); err != nil {
+			t.Fatalf("write config: %v", err)
+		}
+		origUserConfigDir := os.Getenv("XDG_CONFIG_HOME")
+		defer os.Setenv("XDG_CONFIG_HOME", origUserConfigDir)
+		os.Setenv("XDG_CONFIG_HOME", dir)
+		systemConfigDir = filepath.Join(dir, "missing")
+
+		out := captureWarnings(t, loadConfig)
+
+		if tickIntervalFast != 50*time.Millisecond {
+			t.Fatalf("expected 50ms fast tick to be honored, got %v", tickIntervalFast)
+		}
+		if tickIntervalMedium != minTickInterval {
+			t.Fatalf("expected minimum medium tick to be honored, got %v", tickIntervalMedium)
+		}
+		if tickIntervalSlow != 1*time.Second || !strings.Contains(out, "tickIntervalSlow") {
+			t.Fatalf("expected negative slow tick to fall back with a warning, got %v (%q)", tickIntervalSlow, out)
+		}
+		if warningThreshold != 2*time.Minute {
+			t.Fatalf("expected duration string for warningThreshold, got %v", warningThreshold)
+		}
+	})
+}
+
+func TestLoadConfigWarningPercent(t *testing.T) {
+	defer resetGlobals()
+	withTempDir(t, func(dir string) {
+		configDir := filepath.Join(dir, "go-timer")
+		if err := os.MkdirAll(configDir, 0755); err != nil {
+			t.Fatalf("MkdirAll: %v", err)
+		}
+		t.Setenv("XDG_CONFIG_HOME", dir)
+		systemConfigDir = filepath.Join(dir, "missing")
+		write := func(data string) {
+			if err := os.WriteFile(filepath.Join(configDir, "config.json"), []byte(data), 0644); err != nil {
+				t.Fatalf("write config: %v", err)
+			}
+		}
+
+		write(`{"warningThreshold": "10%"}`)
+		if out := captureWarnings(t, loadConfig); out != "" {
+			t.Fatalf("unexpected warnings %q", out)
+		}
+		var none *SessionOverrides
+		if got := none.thresholdFor(10 * time.Minute); got != time.Minute {
+			t.Fatalf("expected 10%% of 10m to be 1m, got %v", got)
+		}
+		if got := none.thresholdFor(2 * time.Hour); got != 12*time.Minute {
+			t.Fatalf("expected 10%% of 2h to be 12m, got %v", got)
+		}
+		session := &SessionOverrides{WarningThreshold: 3 * time.Minute}
+		if got := session.thresholdFor(2 * time.Hour); got != 3*time.Minute {
+			t.Fatalf("expected the session override to win, got %v", got)
+		}
+
+		for _, bad := range []string{"0%", "150%", "ten%"} {
+			resetGlobals()
+			systemConfigDir = filepath.Join(dir, "missing")
+			write(`{"warningThreshold": "` + bad + `"}`)
+			out := captureWarnings(t, loadConfig)
+			if !strings.Contains(out, "warningThreshold") || warningPercent != 0 {
+				t.Fatalf("%s: expected a warning and no percentage, got %v (%q)", bad, warningPercent, out)
+			}
+			if got := none.thresholdFor(10 * time.Minute); got != 5*time.Minute {
+				t.Fatalf("%s: expected the default threshold, got %v", bad, got)
+			}
+		}
+	})
+}
+
+func TestLoadConfigMerge(t *testing.T) {
+	defer resetGlobals()
+	withTempDir(t, func(dir string) {
+		systemDir := filepath.Join(dir, "etc")
+		userDir := filepath.Join(dir, "home")
+		for path, data := range map[string]string{
+			filepath.Join(systemDir, "go-timer", "config.json"): `{"glyphSpacing": 3, "warningThreshold": 600000000000}`,
+			filepath.Join(userDir, "go-timer", "config.json"):   `{"warningThreshold": 120000000000}`,
+		} {
+			if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+				t.Fatalf("MkdirAll: %v", err)
+			}
+			if err := os.WriteFile(path, []byte(data), 0644); err != nil {
+				t.Fatalf("write config: %v", err)
+			}
+		}
+
+		origUserConfigDir := os.Getenv("XDG_CONFIG_HOME")
+		defer os.Setenv("XDG_CONFIG_HOME", origUserConfigDir)
+		os.Setenv("XDG_CONFIG_HOME", userDir)
+		systemConfigDir = systemDir
+
+		loadConfig()
+
+		if glyphSpacing != 3 {
+			t.Fatalf("expected glyphSpacing from system config, got %d", glyphSpacing)
+		}
+		if warningThreshold != 2*time.Minute {
+			t.Fatalf("expected user warningThreshold to win, got %v", warningThreshold)
+		}
+		if glyphWidth != 8 || tickIntervalFast != 100*time.Millisecond {
+			t.Fatalf("fields missing from both files should keep defaults")
+		}
+	})
+}
+
+func TestLoadConfigWrongType(t *testing.T) {
+	defer resetGlobals()
+	out := loadTestConfig(t, `{"restore": "yes", "rounding": "floor", "glyphSpacing": 3}`)
+	if !strings.Contains(out, "restore") || strings.Contains(out, "rounding") {
+		t.Fatalf("expected a warning for restore only, got %q", out)
+	}
+	if restoreEnabled || rounding != "floor" || glyphSpacing != 3 {
+		t.Fatalf("expected the other keys applied, got restore=%v rounding=%q glyphSpacing=%d", restoreEnabled, rounding, glyphSpacing)
+	}
+}
+
+func TestLoadConfigTOML(t *testing.T) {
+	defer resetGlobals()
+	withTempDir(t, func(dir string) {
+		configDir := filepath.Join(dir, "go-timer")
+		if err := os.MkdirAll(configDir, 0755); err != nil {
+			t.Fatalf("MkdirAll: %v", err)
+		}
+		data := `# Work laptop
+warningThreshold = "10m"   # longer warning
+glyphSpacing = 2
+heartbeat = true
+color = 'cyan'
+`
+		if err := os.WriteFile(filepath.Join(configDir, "config.toml"), []byte(data), 0644); err != nil {
+			t.Fatalf("write config: %v", err)
+		}
+		origUserConfigDir := os.Getenv("XDG_CONFIG_HOME")
+		defer os.Setenv("XDG_CONFIG_HOME", origUserConfigDir)
+		os.Setenv("XDG_CONFIG_HOME", dir)
+		systemConfigDir = filepath.Join(dir, "missing")
+
+		if out := captureWarnings(t, loadConfig); out != "" {
+			t.Fatalf("unexpected warning: %q", out)
+		}
+		if warningThreshold != 10*time.Minute || glyphSpacing != 2 || !heartbeatEnabled || defaultColor != colorCodes["cyan"] {
+			t.Fatalf("config.toml not applied: threshold=%v spacing=%d heartbeat=%v color=%q",
+				warningThreshold, glyphSpacing, heartbeatEnabled, defaultColor)
+		}
+
+		// config.json takes precedence when both exist
+		if err := os.WriteFile(filepath.Join(configDir, "config.json"), []byte(`{"glyphSpacing": 4}`), 0644); err != nil {
+			t.Fatalf("write config: %v", err)
+		}
+		resetGlobals()
+		systemConfigDir = filepath.Join(dir, "missing")
+		out := captureWarnings(t, loadConfig)
+		if glyphSpacing != 4 || heartbeatEnabled || !strings.Contains(out, "config.toml") {
+			t.Fatalf("expected config.json to win with a warning, got spacing=%d (%q)", glyphSpacing, out)
+		}
+
+		// Invalid TOML is reported and skipped
+		os.Remove(filepath.Join(configDir, "config.json"))
+		if err := os.WriteFile(filepath.Join(configDir, "config.toml"), []byte("[timer]\n"), 0644); err != nil {
+			t.Fatalf("write config: %v", err)
+		}
+		resetGlobals()
+		systemConfigDir = filepath.Join(dir, "missing")
+		if out := captureWarnings(t, loadConfig); !strings.Contains(out, "invalid config") {
+			t.Fatalf("expected invalid config warning, got %q", out)
+		}
+	})
+}
+
+func TestLoadConfigProfile(t *testing.T) {
+	defer resetGlobals()
+	withTempDir(t, func(dir string) {
+		configDir := filepath.Join(dir, "go-timer")
+		if err := os.MkdirAll(configDir, 0755); err != nil {
+			t.Fatalf("MkdirAll: %v", err)
+		}
+		for file, data := range map[string]string{
+			"config.json":      `{"glyphSpacing": 2}`,
+			"config.work.json": `{"glyphSpacing": 4}`,
+			"config.home.toml": `glyphSpacing = 5`,
+		} {
+			if err := os.WriteFile(filepath.Join(configDir, file), []byte(data), 0644); err != nil {
+				t.Fatalf("write config: %v", err)
+			}
+		}
+		t.Setenv("XDG_CONFIG_HOME", dir)
+
+		for profile, want := range map[string]int{"": 2, "work": 4, "home": 5} {
+			resetGlobals()
+			systemConfigDir = filepath.Join(dir, "missing")
+			setConfigProfile(profile)
+			if out := captureWarnings(t, loadConfig); out != "" || glyphSpacing != want {
+				t.Errorf("profile %q: glyphSpacing = %d (%q), want %d", profile, glyphSpacing, out, want)
+			}
+		}
+
+		// Unknown profiles fall back to config.json with a warning
+		resetGlobals()
+		systemConfigDir = filepath.Join(dir, "missing")
+		setConfigProfile("gym")
+		if out := captureWarnings(t, loadConfig); glyphSpacing != 2 || !strings.Contains(out, `"gym" not found`) {
+			t.Fatalf("expected fallback to config.json with a warning, got %d (%q)", glyphSpacing, out)
+		}
+		if out := captureWarnings(t, func() { setConfigProfile("../etc") }); configProfile != "" || out == "" {
+			t.Fatalf("expected invalid profile name to be rejected, got %q", configProfile)
+		}
+	})
+}
+
+// captureWarnings collects warnings printed while fn runs
+func captureWarnings(t *testing.T, fn func()) string {
+	t.Helper()
+	var buf bytes.Buffer
+	orig := warnOut
+	warnOut = &buf
+	defer func() { warnOut = orig }()
+	fn()
+	return buf.String()
+}
+
+// testConfigDir points the config search at a fresh, empty user config dir with
+// no system config, and returns its go-timer directory for config files
+func testConfigDir(t *testing.T) string {
+	t.Helper()
+	dir := t.TempDir()
+	t.Setenv("XDG_CONFIG_HOME", dir)
+	systemConfigDir = filepath.Join(dir, "missing")
+	configDir := filepath.Join(dir, "go-timer")
+	if err := os.MkdirAll(configDir, 0755); err != nil {
+		t.Fatalf("MkdirAll: %v", err)
+	}
+	return configDir
+}
+
+// writeTestConfig writes a config file into a dir from testConfigDir
+func writeTestConfig(t *testing.T, configDir, file, data string) {
+	t.Helper()
+	if err := os.WriteFile(filepath.Join(configDir, file), []byte(data), 0644); err != nil {
+		t.Fatalf("write config: %v", err)
+	}
+}
+
+// loadTestConfig loads data as the only config.json and returns the warnings
+func loadTestConfig(t *testing.T, data string) string {
+	t.Helper()
+	writeTestConfig(t, testConfigDir(t), "config.json", data)
+	return captureWarnings(t, loadConfig)
+}
+
+func TestEditHelpers(t *testing.T) {
+	defer resetGlobals()
+	t.Setenv("VISUAL", "")
+	t.Setenv("EDITOR", "")
+	if cmd := editorCommand(); cmd != nil {
+		t.Fatalf("expected no editor, got %q", cmd)
+	}
+	t.Setenv("EDITOR", "code  --wait")
+	if cmd := editorCommand(); len(cmd) != 2 || cmd[0] != "code" || cmd[1] != "--wait" {
+		t.Fatalf("expected [code --wait], got %q", cmd)
+	}
+	t.Setenv("VISUAL", "vim")
+	if cmd := editorCommand(); len(cmd) != 1 || cmd[0] != "vim" {
+		t.Fatalf("expected $VISUAL to win, got %q", cmd)
+	}
+
+	withTempDir(t, func(dir string) {
+		t.Setenv("XDG_CONFIG_HOME", dir)
+		systemConfigDir = filepath.Join(dir, "missing")
+
+		path := editConfigPath()
+		if path != filepath.Join(dir, "go-timer", "config.toml") {
+			t.Fatalf("expected a new config.toml, got %s", path)
+		}
+		if created, err := ensureFile(path, configTemplate); !created || err != nil {
+			t.Fatalf("ensureFile: %v, %v", created, err)
+		}
+		if problems := validateEditedFile(path, false); len(problems) != 0 {
+			t.Fatalf("template should be a valid config, got %v", problems)
+		}
+		if fields, err := decodeTOMLConfig([]byte(configTemplate)); err != nil || len(fields) != 0 {
+			t.Fatalf("template should set nothing, got %v, %v", fields, err)
+		}
+
+		// An existing file is opened as is
+		if err := os.WriteFile(path, []byte("rounding = \"sideways\"\n"), 0644); err != nil {
+			t.Fatalf("WriteFile: %v", err)
+		}
+		if created, err := ensureFile(path, configTemplate); created || err != nil {
+			t.Fatalf("ensureFile must keep an existing file: %v, %v", created, err)
+		}
+		if problems := validateEditedFile(path, false); len(problems) != 1 || !strings.Contains(problems[0], "rounding") {
+			t.Fatalf("expected the bad rounding to be reported, got %v", problems)
+		}
+
+		// Only the edited file is checked, not a broken system config
+		systemConfigDir = t.TempDir()
+		os.Mkdir(filepath.Join(systemConfigDir, "go-timer"), 0755)
+		writeTestConfig(t, filepath.Join(systemConfigDir, "go-timer"), "config.json", `{"glyphStyle": "fancy"}`)
+		if err := os.WriteFile(path, []byte("rounding = \"floor\"\n"), 0644); err != nil {
+			t.Fatalf("WriteFile: %v", err)
+		}
+		if problems := validateEditedFile(path, false); len(problems) != 0 {
+			t.Fatalf("expected the system config left out, got %v", problems)
+		}
+
+		// A value of the wrong type is listed, and the other keys still checked
+		if err := os.WriteFile(path, []byte("restore = \"yes\"\nrounding = \"floor\"\n"), 0644); err != nil {
+			t.Fatalf("WriteFile: %v", err)
+		}
+		if problems := validateEditedFile(path, false); len(problems) != 1 || !strings.Contains(problems[0], "restore") {
+			t.Fatalf("expected the bad restore to be reported, got %v", problems)
+		}
+		if rounding != "floor" {
+			t.Fatalf("expected rounding applied next to the bad key, got %q", rounding)
+		}
+
+		// With no editor the path is printed to edit by hand
+		t.Setenv("VISUAL", "")
+		t.Setenv("EDITOR", "")
+		r, w, err := os.Pipe()
+		if err != nil {
+			t.Fatalf("Pipe: %v", err)
+		}
+		origStdout := os.Stdout
+		os.Stdout = w
+		err = runEdit(false)
+		os.Stdout = origStdout
+		w.Close()
+		if out, _ := io.ReadAll(r); err != nil || string(out) != path+"\n" {
+			t.Fatalf("expected just the path printed, got %q, %v", out, err)
+		}
+
+		sessions := filepath.Join(dir, "sessions.json")
+		if err := os.WriteFile(sessions, []byte(`{"a": {"mode": "timer"}, "a": {"mode": "counter"}}`), 0644); err != nil {
+			t.Fatalf("WriteFile: %v", err)
+		}
+		if problems := validateEditedFile(sessions, true); len(problems) == 0 {
+			t.Fatalf("expected the duplicate session to be reported")
+		}
+	})
+}
+
+func TestLoadConfigMissing(t *testing.T) {
+	defer resetGlobals()
+	withTempDir(t, func(dir string) {
+		origUserConfigDir := os.Getenv("XDG_CONFIG_HOME")
+		defer os.Setenv("XDG_CONFIG_HOME", origUserConfigDir)
+		os.Setenv("XDG_CONFIG_HOME", dir)
+		systemConfigDir = filepath.Join(dir, "missing")
+
+		if out := captureWarnings(t, loadConfig); out != "" {
+			t.Fatalf("expected no warning for missing config, got %q", out)
+		}
+		if warningThreshold != 5*time.Minute {
+			t.Fatalf("missing config should keep defaults")
+		}
+	})
+}
+
+func TestLoadConfigUnreadable(t *testing.T) {
+	defer resetGlobals()
+	withTempDir(t, func(dir string) {
+		// config.json is a directory
+		if err := os.MkdirAll(filepath.Join(dir, "go-timer", "config.json"), 0755); err != nil {
+			t.Fatalf("MkdirAll: %v", err)
+		}
+		origUserConfigDir := os.Getenv("XDG_CONFIG_HOME")
+		defer os.Setenv("XDG_CONFIG_HOME", origUserConfigDir)
+		os.Setenv("XDG_CONFIG_HOME", dir)
+		systemConfigDir = filepath.Join(dir, "missing")
+
+		out := captureWarnings(t, loadConfig)
+		if !strings.Contains(out, "cannot read config") {
+			t.Fatalf("expected warning for unreadable config, got %q", out)
+		}
+		if warningThreshold != 5*time.Minute {
+			t.Fatalf("unreadable config should keep defaults")
+		}
+	})
+}
+
+func TestLoadConfigNoUserConfigDir(t *testing.T) {
+	defer resetGlobals()
+	withTempDir(t, func(dir string) {
+		cwd, err := os.Getwd()
+		if err != nil {
+			t.Fatalf("Getwd: %v", err)
+		}
+		defer os.Chdir(cwd)
+		if err := os.Chdir(dir); err != nil {
+			t.Fatalf("Chdir: %v", err)
+		}
+		// Minimal environment: no XDG_CONFIG_HOME and no HOME
+		t.Setenv("XDG_CONFIG_HOME", "")
+		t.Setenv("HOME", "")
+		t.Setenv("AppData", "")
+		systemConfigDir = filepath.Join(dir, "missing")
+
+		if err := os.MkdirAll("go-timer", 0755); err != nil {
+			t.Fatalf("MkdirAll: %v", err)
+		}
+		if err := os.WriteFile(filepath.Join("go-timer", "config.json"), []byte(`{"warningThreshold": "2m"}`), 0644); err != nil {
+			t.Fatalf("WriteFile: %v", err)
+		}
+
+		out := captureWarnings(t, loadConfig)
+		if !strings.Contains(out, "cannot determine user config dir") {
+			t.Fatalf("expected warning about the config dir, got %q", out)
+		}
+		if warningThreshold != 2*time.Minute {
+			t.Fatalf("expected config from ./go-timer, got warningThreshold %v", warningThreshold)
+		}
+	})
+}
+
+func TestWriteAndLoadSessionCompatibility(t *testing.T) {
+	withTempDir(t, func(dir string) {
+		cwd, err := os.Getwd()
+		if err != nil {
+			t.Fatalf("Getwd: %v", err)
+		}
+		defer os.Chdir(cwd)
+		if err := os.Chdir(dir); err != nil {
+			t.Fatalf("Chdir: %v", err)
+		}
+
+		s := Session{
+			Start:    "2024-01-01:00-00-00",
+			Current:  "2024-01-01:00-00-10",
+			Elapsed:  "10.0s",
+			Mode:     "timer",
+			Name:     "",
+			Finished: true,
+			Inline:   true,
+		}
+		writeSession(s)
+		loaded, err := loadSession("")
+		if err != nil {
+			t.Fatalf("loadSession after write: %v", err)
+		}
+		if loaded.Elapsed != s.Elapsed || loaded.Mode != s.Mode || loaded.Finished != s.Finished {
+			t.Fatalf("loaded session does not match written")
+		}
+	})
+}
+
+func TestRestoredElapsed(t *testing.T) {
+	defer resetGlobals()
+	at := time.Date(2024, 1, 1, 10, 0, 0, 0, time.Local)
+	// Killed at 09:50 with 5 minutes elapsed, restored at 10:00
+	killed := Session{Mode: "timer", Current: "2024-01-01:09-50-00", Elapsed: "300.0s", Remaining: "1200.0s"}
+
+	if got := restoredElapsed(killed, at); got != 5*time.Minute {
+		t.Fatalf("freeze: expected the stored 5m, got %v", got)
+	}
+
+	restoreDowntime = "continue"
+	if got := restoredElapsed(killed, at); got != 15*time.Minute {
+		t.Fatalf("continue: expected 5m + 10m down, got %v", got)
+	}
+	paused := killed
+	paused.Paused = true
+	if got := restoredElapsed(paused, at); got != 5*time.Minute {
+		t.Fatalf("continue: a paused session must not catch up, got %v", got)
+	}
+	finished := killed
+	finished.Finished = true
+	if got := restoredElapsed(finished, at); got != 5*time.Minute {
+		t.Fatalf("continue: a finished session must not catch up, got %v", got)
+	}
+	future := killed
+	future.Current = "2024-01-01:10-30-00"
+	if got := restoredElapsed(future, at); got != 5*time.Minute {
+		t.Fatalf("continue: a timestamp in the future must be ignored, got %v", got)
+	}
+}
+
+func TestSessionView(t *testing.T) {
+	defer resetGlobals()
+	at := time.Date(2024, 1, 1, 9, 0, 10, 0, time.Local)
+	written := at.Add(-2 * time.Second).Format(sessionTimeLayout)
+	stale := at.Add(-time.Hour).Format(sessionTimeLayout)
+
+	cases := []struct {
+		name    string
+		session Session
+		want    string
+		color   string
+	}{
+		{"running countdown counts on", Session{Mode: "timer", Current: written, Elapsed: "60.0s", Remaining: "400.0s"}, "06:38", ""},
+		{"stale countdown shows as stored", Session{Mode: "timer", Current: stale, Elapsed: "60.0s", Remaining: "400.0s"}, "06:40", ""},
+		{"paused countdown", Session{Mode: "timer", Current: written, Remaining: "100.0s", Paused: true}, "01:40", blueColor},
+		{"countdown in warning period", Session{Mode: "timer", Current: written, Remaining: "61.0s"}, "00:59", redColor},
+		{"finished countdown", Session{Mode: "timer", Current: written, Remaining: "0s", Finished: true}, "00:00", redColor},
+		{"running counter", Session{Mode: "counter", Current: written, Elapsed: "10.0s"}, "00:12", ""},
+		{"counter past goal", Session{Mode: "counter", Current: stale, Elapsed: "70.0s", Goal: "60.0s"}, "+00:10", yellowColor},
+		{"countdown in overtime", Session{Mode: "timer", Current: written, Remaining: "0s", Overtime: "30.0s", Finished: true}, "-00:32", redColor},
+	}
+	for _, c := range cases {
+		got, color := sessionView(c.session, at)
+		if got != c.want || color != c.color {
+			t.Errorf("%s: got %q %q, want %q %q", c.name, got, color, c.want, c.color)
+		}
+	}
+}
+
+func TestWatchLines(t *testing.T) {
+	defer resetGlobals()
+	at := time.Date(2024, 1, 1, 9, 0, 10, 0, time.Local)
+	written := at.Add(-2 * time.Second).Format(sessionTimeLayout)
+	sessions := map[string]Session{
+		"writing": {Name: "writing", Group: "work", Mode: "counter", Current: written, Elapsed: "60.0s"},
+		"email":   {Name: "email", Group: "work", Mode: "counter", Current: written, Elapsed: "30.0s", Paused: true},
+		"gym":     {Name: "gym", Group: "health", Mode: "counter", Current: written, Elapsed: "600.0s"},
+		"tea":     {Name: "tea", Mode: "timer", Current: written, Elapsed: "10.0s", Remaining: "170.0s"},
+	}
+	lines, total := watchLines(sessions, "work", at)
+	want := []string{
+		"email    00:30 (paused)",
+		"writing  01:02         ",
+	}
+	if strings.Join(lines, "|") != strings.Join(want, "|") {
+		t.Errorf("got lines %q, want %q", lines, want)
+	}
+	// The running member counts on from its last write
+	if total != 92*time.Second {
+		t.Errorf("got total %v, want 1m32s", total)
+	}
+	if lines, total := watchLines(sessions, "none", at); len(lines) != 0 || total != 0 {
+		t.Errorf("expected an empty group, got %q %v", lines, total)
+	}
+}
+
+func TestInfluxPoint(t *testing.T) {
+	defer resetGlobals()
+	at := time.Unix(1700000000, 0)
+	if got, want := influxPoint("work", "timer", 272, 28, false, at), "timer,mode=timer,name=work remaining=272,elapsed=28,paused=false 1700000000000000000\n"; got != want {
+		t.Fatalf("got %q, want %q", got, want)
+	}
+
+	// Configured measurement and tags, escaped and sorted; counters have no remaining
+	dir := t.TempDir()
+	t.Setenv("XDG_CONFIG_HOME", dir)
+	systemConfigDir = filepath.Join(dir, "missing")
+	load := func(data string) string {
+		if err := os.MkdirAll(filepath.Join(dir, "go-timer"), 0755); err != nil {
+			t.Fatalf("MkdirAll: %v", err)
+		}
+		if err := os.WriteFile(filepath.Join(dir, "go-timer", "config.json"), []byte(data), 0644); err != nil {
+			t.Fatalf("write config: %v", err)
+		}
+		return captureWarnings(t, loadConfig)
+	}
+	if warnings := load(`{"influxMeasurement": "", "influxTags": "host"}`); !strings.Contains(warnings, "influxMeasurement") || !strings.Contains(warnings, "influxTags") || influxTags != nil {
+		t.Fatalf("expected warnings for an empty measurement and a malformed tag, got %q", warnings)
+	}
+	load(`{"influxMeasurement": "focus time", "influxTags": "host=my laptop, zone=eu,mode=ignored"}`)
+	if got, want := influxPoint("", "counter", 90, 90, true, at), "focus\\ time,host=my\\ laptop,mode=counter,zone=eu elapsed=90,paused=true 1700000000000000000\n"; got != want {
+		t.Fatalf("got %q, want %q", got, want)
+	}
+
+	// UDP destinations get one datagram per point
+	conn, err := net.ListenPacket("udp", "127.0.0.1:0")
+	if err != nil {
+		t.Skipf("no UDP: %v", err)
+	}
+	defer conn.Close()
+	w, err := openInflux("udp://" + conn.LocalAddr().String())
+	if err != nil {
+		t.Fatalf("openInflux: %v", err)
+	}
+	defer w.Close()
+	fmt.Fprint(w, "timer elapsed=1 1\n")
+	buf := make([]byte, 64)
+	conn.SetReadDeadline(time.Now().Add(3 * time.Second))
+	if n, _, err := conn.ReadFrom(buf); err != nil || string(buf[:n]) != "timer elapsed=1 1\n" {
+		t.Fatalf("expected the point over UDP, got %q (%v)", buf[:n], err)
+	}
+	if _, err := openInflux("localhost"); exitCode(err) != exitUsage {
+		t.Fatalf("expected a usage error for a destination without a port, got %v", err)
+	}
+}
+
+func TestRunTimerInflux(t *testing.T) {
+	defer resetGlobals()
+	persistSessions = false
+	t.Setenv("PATH", t.TempDir()) // No notifier
+	keys := make(chan []byte)
+	captureFrames(t, keys)
+	origStdin := stdinIsTerminal
+	defer func() { stdinIsTerminal = origStdin }()
+
+	// Run from a pipe: no raw mode, and points until the countdown finishes
+	stdinIsTerminal = func() bool { return false }
+	enterRawMode = func() (func(), error) {
+		t.Error("raw mode set up without a terminal on stdin")
+		return func() {}, nil
+	}
+	var points bytes.Buffer
+	summaryCh := make(chan TimerSummary, 1)
+	if err := runTimer(timerOptions{Name: "tea", Duration: 1500 * time.Millisecond, Influx: &points}, summaryCh); err != nil {
+		t.Fatalf("runTimer: %v", err)
+	}
+	if summary := <-summaryCh; !summary.Finished {
+		t.Fatalf("expected the countdown to finish, got %+v", summary)
+	}
+	lines := strings.Split(strings.TrimSpace(points.String()), "\n")
+	if len(lines) < 2 || !strings.HasPrefix(lines[0], "timer,mode=timer,name=tea remaining=2,elapsed=0,paused=false ") {
+		t.Fatalf("expected a point per second starting at 2s remaining, got %q", points.String())
+	}
+	for _, line := range lines {
+		if !strings.HasPrefix(line, "timer,mode=timer,name=tea remaining=") {
+			t.Fatalf("expected only points on the influx writer, got %q", line)
+		}
+	}
+}
+
+// fakeSyslog records the lines the event log writes
+type fakeSyslog struct {
+	lines  []string
+	closed bool
+}
+
+func (f *fakeSyslog) Info(m string) error { f.lines = append(f.lines, m); return nil }
+func (f *fakeSyslog) Close() error        { f.closed = true; return nil }
+
+func TestEventLog(t *testing.T) {
+	defer resetGlobals()
+	fake := &fakeSyslog{}
+	origOpen := openSyslog
+	defer func() { openSyslog = origOpen }()
+	openSyslog = func() (syslogWriter, error) { return fake, nil }
+
+	// Disabled: nothing is opened and recording is a no-op
+	if events := openEventLog(); events != nil {
+		t.Fatalf("expected no event log while syslog is off")
+	}
+	var off *eventLog
+	off.record("start", "work", "timer", 0)
+	off.close()
+
+	syslogEnabled = true
+	events := openEventLog()
+	events.record("start", "work", "timer", 0)
+	events.record("finish", "", "counter", 1500*time.Second)
+	events.close()
+	want := []string{"start name=work mode=timer elapsed=0s", "finish name=default mode=counter elapsed=1500.0s"}
+	if strings.Join(fake.lines, "|") != strings.Join(want, "|") || !fake.closed {
+		t.Fatalf("expected %q and a close, got %q (closed %v)", want, fake.lines, fake.closed)
+	}
+
+	// Unavailable: a warning, and the timer carries on without it
+	openSyslog = func() (syslogWriter, error) { return nil, errors.New("no syslog daemon") }
+	var unavailable *eventLog
+	out := captureWarnings(t, func() { unavailable = openEventLog() })
+	if unavailable != nil || !strings.Contains(out, "syslog unavailable: no syslog daemon") {
+		t.Fatalf("expected a warning and no event log, got %v (%q)", unavailable, out)
+	}
+	unavailable.record("stop", "work", "timer", time.Second)
+}
+
+func TestPromptToken(t *testing.T) {
+	defer resetGlobals()
+	at := time.Date(2024, 1, 1, 9, 0, 10, 0, time.Local)
+	written := at.Add(-2 * time.Second).Format(sessionTimeLayout)
+	newer := at.Add(-time.Second).Format(sessionTimeLayout)
+	stale := at.Add(-time.Hour).Format(sessionTimeLayout)
+	sessions := map[string]Session{
+		"tea":   {Name: "tea", Mode: "timer", Current: written, Elapsed: "10.0s", Remaining: "274.0s"},
+		"run":   {Name: "run", Mode: "counter", Current: newer, Elapsed: "60.0s"},
+		"old":   {Name: "old", Mode: "counter", Current: stale, Elapsed: "60.0s"},
+		"break": {Name: "break", Mode: "timer", Current: newer, Remaining: "60.0s", Paused: true},
+	}
+	if got := promptToken(sessions, "", at); got != "[01:01]" {
+		t.Errorf("expected the most recently written running session, got %q", got)
+	}
+	if got := promptToken(sessions, "tea", at); got != "[04:32]" {
+		t.Errorf("expected tea counting down from its last write, got %q", got)
+	}
+	for _, name := range []string{"old", "break", "missing"} {
+		if got := promptToken(sessions, name, at); got != "" {
+			t.Errorf("%s: expected nothing for a session that isn't running, got %q", name, got)
+		}
+	}
+}
+
+func TestSplitPane(t *testing.T) {
+	defer resetGlobals()
+	glyphs, glyphWidth, glyphHeight = blockGlyphs, 6, 5
+	out := splitPane("tea", "01:00", "", 41, 40, 24)
+	if !strings.HasPrefix(out, moveCursor(8, 41+(40-3)/2)+"tea") {
+		t.Fatalf("expected the label centered above the digits, got %q", out)
+	}
+	// 5 glyphs of 6 columns plus 4 spaces = 34 wide, centered in 40 columns from 41
+	if !strings.Contains(out, moveCursor(9, 44)) || !strings.Contains(out, moveCursor(13, 44)) {
+		t.Fatalf("expected digit rows 9-13 starting at column 44, got %q", out)
+	}
+	// Too narrow for big digits: plain text instead
+	if out := splitPane("tea", "01:00", redColor, 1, 20, 24); !strings.Contains(out, redColor+moveCursor(11, 8)+"01:00"+resetStyle) {
+		t.Fatalf("expected plain colored text in a narrow pane, got %q", out)
+	}
+}
+
+func TestWaitForSession(t *testing.T) {
+	defer resetGlobals()
+	withTempDir(t, func(dir string) {
+		cwd, err := os.Getwd()
+		if err != nil {
+			t.Fatalf("Getwd: %v", err)
+		}
+		defer os.Chdir(cwd)
+		if err := os.Chdir(dir); err != nil {
+			t.Fatalf("Chdir: %v", err)
+		}
+		advance := setClock(t, time.Date(2024, 1, 1, 9, 0, 0, 0, time.UTC))
+		var polls []time.Duration
+		var onPoll func()
+		origSleep := sleep
+		sleep = func(d time.Duration) {
+			polls = append(polls, d)
+			advance(d)
+			if onPoll != nil {
+				onPoll()
+			}
+		}
+		defer func() { sleep = origSleep }()
+		write := func(content string) {
+			t.Helper()
+			if err := os.WriteFile("sessions.json", []byte(content), 0644); err != nil {
+				t.Fatalf("write sessions: %v", err)
+			}
+		}
+
+		// Never appears: gives up at the timeout
+		if err := waitForSession("steep", 3*time.Second); err == nil || !strings.Contains(err.Error(), "did not appear") {
+			t.Fatalf("expected a did-not-appear error, got %v", err)
+		}
+
+		// A long countdown is polled slowly, then finishes
+		write(`{"steep": {"mode": "timer", "name": "steep", "remaining": "600.0s"}}`)
+		polls = nil
+		onPoll = func() { write(`{"steep": {"mode": "timer", "name": "steep", "finished": true}}`) }
+		if err := waitForSession("steep", 0); err != nil {
+			t.Fatalf("expected success once finished, got %v", err)
+		}
+		if len(polls) != 1 || polls[0] != tickIntervalSlow {
+			t.Fatalf("expected one slow poll, got %v", polls)
+		}
+
+		// Deleted while still running
+		write(`{"steep": {"mode": "timer", "name": "steep", "remaining": "30.0s"}}`)
+		polls = nil
+		onPoll = func() { write(`{}`) }
+		if err := waitForSession("steep", 0); err == nil || !strings.Contains(err.Error(), "deleted") {
+			t.Fatalf("expected a deleted error, got %v", err)
+		}
+		if polls[0] != tickIntervalFast {
+			t.Fatalf("expected a fast poll near the end, got %v", polls)
+		}
+
+		// Finished before waiting began: waits for the next run to finish
+		write(`{"steep": {"mode": "timer", "name": "steep", "start": "2024-01-01:08-00-00", "finished": true}}`)
+		polls = nil
+		runs := []string{
+			`{"steep": {"mode": "timer", "name": "steep", "start": "2024-01-01:08-00-00", "finished": true}}`,
+			`{"steep": {"mode": "timer", "name": "steep", "start": "2024-01-01:09-00-05", "remaining": "300.0s"}}`,
+			`{"steep": {"mode": "timer", "name": "steep", "start": "2024-01-01:09-00-05", "finished": true}}`,
+		}
+		onPoll = func() {
+			write(runs[0])
+			runs = runs[1:]
+		}
+		if err := waitForSession("steep", 0); err != nil {
+			t.Fatalf("expected success once the new run finished, got %v", err)
+		}
+		if len(polls) != 3 || polls[0] != tickIntervalSlow {
+			t.Fatalf("expected three polls, the first slow, got %v", polls)
+		}
+
+		// Still running at the timeout
+		write(`{"default": {"mode": "counter"}}`)
+		onPoll = nil
+		if err := waitForSession("", 2*time.Second); err == nil || !strings.Contains(err.Error(), "did not finish") {
+			t.Fatalf("expected a did-not-finish error, got %v", err)
+		}
+	})
+}
+
+func TestWriteSessionKeepsOtherWriters(t *testing.T) {
+	withTempDir(t, func(dir string) {
+		cwd, err := os.Getwd()
+		if err != nil {
+			t.Fatalf("Getwd: %v", err)
+		}
+		defer os.Chdir(cwd)
+		if err := os.Chdir(dir); err != nil {
+			t.Fatalf("Chdir: %v", err)
+		}
+
+		writeSession(Session{Name: "a", Mode: "timer"})
+		// Another process adds a session behind our back
+		if err := os.WriteFile("sessions.json", []byte(`{"a": {"mode": "timer", "name": "a"}, "other": {"mode": "counter", "name": "other"}}`), 0644); err != nil {
+			t.Fatalf("write sessions: %v", err)
+		}
+		writeSession(Session{Name: "b", Mode: "timer"})
+
+		data, err := os.ReadFile("sessions.json")
+		if err != nil {
+			t.Fatalf("read sessions: %v", err)
+		}
+		var sessions map[string]Session
+		if err := json.Unmarshal(data, &sessions); err != nil {
+			t.Fatalf("sessions.json is not valid JSON: %v\n%s", err, data)
+		}
+		if _, ok := sessions["other"]; !ok || len(sessions) != 3 {
+			t.Fatalf("expected a, b and other, got %v", sessions)
+		}
+	})
+}
+
+func TestEncodeSessions(t *testing.T) {
+	session := Session{Name: "work", Mode: "counter", Elapsed: "1.0s"}
+	raw, err := json.MarshalIndent(session, "  ", "  ")
+	if err != nil {
+		t.Fatalf("MarshalIndent: %v", err)
+	}
+	want, err := json.MarshalIndent(map[string]Session{"work": session}, "", "  ")
+	if err != nil {
+		t.Fatalf("MarshalIndent: %v", err)
+	}
+	if got := encodeSessions(map[string]json.RawMessage{"work": raw}); string(got) != string(want) {
+		t.Fatalf("encodeSessions = %s, want %s", got, want)
+	}
+	if got := encodeSessions(nil); string(got) != "{}" {
+		t.Fatalf("expected {} for no sessions, got %s", got)
+	}
+}
+
+func TestWriteSessionNoPersist(t *testing.T) {
+	defer resetGlobals()
+	withTempDir(t, func(dir string) {
+		cwd, err := os.Getwd()
+		if err != nil {
+			t.Fatalf("Getwd: %v", err)
+		}
+		defer os.Chdir(cwd)
+		if err := os.Chdir(dir); err != nil {
+			t.Fatalf("Chdir: %v", err)
+		}
+
+		persistSessions = false
+		writeSession(Session{Name: "kitchen", Mode: "timer", Elapsed: "1.0s"})
+		if _, err := os.Stat("sessions.json"); !os.IsNotExist(err) {
+			t.Fatalf("expected no sessions.json with persistence disabled, got %v", err)
+		}
+	})
+}
+
+func TestCheckNotifier(t *testing.T) {
+	if runtime.GOOS != "linux" {
+		t.Skip("notifications are Linux only")
+	}
+	defer resetGlobals()
+	t.Setenv("PATH", t.TempDir())
+
+	if out := captureWarnings(t, checkNotifier); !strings.Contains(out, "notify-send not found") {
+		t.Fatalf("expected missing notifier warning, got %q", out)
+	}
+	notifyCheck = false
+	if out := captureWarnings(t, checkNotifier); out != "" {
+		t.Fatalf("expected no warning when notifyCheck is off, got %q", out)
+	}
+}
+
+func TestNotifySnooze(t *testing.T) {
+	if runtime.GOOS != "linux" {
+		t.Skip("notifications are Linux only")
+	}
+	dir := t.TempDir()
+	t.Setenv("PATH", dir)
+	log := filepath.Join(dir, "calls")
+	fake := func(script string) {
+		body := "#!/bin/sh\necho \"$@\" >> " + log + "\n" + script
+		if err := os.WriteFile(filepath.Join(dir, "notify-send"), []byte(body), 0755); err != nil {
+			t.Fatalf("write notify-send: %v", err)
+		}
+		os.Remove(log)
+	}
+
+	// Clicking the action prints its key
+	fake(`case "$1" in --wait) echo snooze ;; esac`)
+	if !notifySnooze(context.Background(), "tea", "Timer finished!", 5*time.Minute) {
+		t.Fatal("expected the snooze click to be reported")
+	}
+	if calls, _ := os.ReadFile(log); !strings.Contains(string(calls), "--action=snooze=Snooze 5 minutes tea Timer finished!") {
+		t.Fatalf("unexpected notify-send call %q", calls)
+	}
+
+	// Dismissed or expired: nothing printed
+	fake("")
+	if notifySnooze(context.Background(), "tea", "Timer finished!", 5*time.Minute) {
+		t.Fatal("expected no snooze without a click")
+	}
+
+	// A notifier without --action falls back to a plain notification
+	fake(`case "$1" in --wait) exit 1 ;; esac`)
+	if notifySnooze(context.Background(), "", "Timer finished!", 5*time.Minute) {
+		t.Fatal("expected no snooze from an unsupported notifier")
+	}
+	calls, _ := os.ReadFile(log)
+	if lines := strings.Split(strings.TrimSpace(string(calls)), "\n"); len(lines) != 2 || lines[1] != "Timer Timer finished!" {
+		t.Fatalf("expected a plain fallback notification, got %q", calls)
+	}
+}
+
+func TestSnoozeOptions(t *testing.T) {
+	defer resetGlobals()
+	snoozeDuration = 5 * time.Minute
+	first := timerOptions{
+		Duration:       25 * time.Minute,
+		Name:           "tea",
+		Fullscreen:     true,
+		InitialElapsed: time.Minute,
+		Pauses:         []PauseInterval{{Start: "2024-01-01:09-00-00", End: "2024-01-01:09-01-00"}},
+		PauseAt:        []time.Duration{10 * time.Minute},
+		Checkpoints:    []time.Duration{time.Minute},
+		WaitForStart:   true,
+	}
+	opts := snoozeOptions(first)
+	if opts.Duration != 5*time.Minute || opts.Name != "tea" || !opts.Fullscreen {
+		t.Fatalf("expected a 5m countdown shown like the first, got %+v", opts)
+	}
+	if opts.InitialElapsed != 0 || opts.Pauses != nil || opts.PauseAt != nil || opts.Checkpoints != nil || opts.WaitForStart {
+		t.Fatalf("expected nothing carried over from the first countdown, got %+v", opts)
+	}
+}
+
+func TestRunTimerSnoozeKeepsKeys(t *testing.T) {
+	if runtime.GOOS != "linux" {
+		t.Skip("notifications are Linux only")
+	}
+	defer resetGlobals()
+	persistSessions = false
+	snoozeDuration = 5 * time.Minute
+	dir := t.TempDir()
+	t.Setenv("PATH", dir)
+	// A notification nobody answers blocks until snoozeWait
+	if err := os.WriteFile(filepath.Join(dir, "notify-send"), []byte("#!/bin/sh\nexec sleep 30\n"), 0755); err != nil {
+		t.Fatalf("write notify-send: %v", err)
+	}
+	keys := make(chan []byte)
+	frames := captureFrames(t, keys)
+
+	summaryCh := make(chan TimerSummary, 1)
+	done := make(chan error, 1)
+	go func() {
+		done <- runTimer(timerOptions{Duration: 100 * time.Millisecond}, summaryCh)
+	}()
+	frames.waitFor(t, "finished!")
+
+	// A key still ends the wait while the notification is up
+	keys <- []byte("x")
+	select {
+	case err := <-done:
+		if err != nil {
+			t.Fatalf("runTimer: %v", err)
+		}
+	case <-time.After(3 * time.Second):
+		t.Fatalf("the pending notification blocked the key")
+	}
+	if summary := <-summaryCh; !summary.Finished || summary.Snoozed {
+		t.Fatalf("expected a finished, unsnoozed summary, got %+v", summary)
+	}
+}
+
+func TestSpeechArgs(t *testing.T) {
+	got := speechArgs([]string{"espeak", "-s", "140"}, "5 minutes remaining")
+	if strings.Join(got, "|") != "-s|140|5 minutes remaining" {
+		t.Fatalf("expected the text appended, got %q", got)
+	}
+	got = speechArgs([]string{"powershell", "-c", "Speak('{text}')"}, "time's up")
+	if strings.Join(got, "|") != "-c|Speak('time's up')" {
+		t.Fatalf("expected the placeholder filled in, got %q", got)
+	}
+}
+
+func TestCheckSpeech(t *testing.T) {
+	defer resetGlobals()
+	dir := t.TempDir()
+	t.Setenv("PATH", dir)
+
+	if out := captureWarnings(t, checkSpeech); out != "" {
+		t.Fatalf("expected no warning with speech off, got %q", out)
+	}
+	speakEnabled = true
+	if out := captureWarnings(t, checkSpeech); !strings.Contains(out, "no text-to-speech program") {
+		t.Fatalf("expected a missing engine warning, got %q", out)
+	}
+	speakCommand = "festival --tts"
+	if out := captureWarnings(t, checkSpeech); !strings.Contains(out, `"festival" not found`) {
+		t.Fatalf("expected a missing speakCommand warning, got %q", out)
+	}
+	speak("tea", "time's up") // Must not fail without an engine
+
+	if err := os.WriteFile(filepath.Join(dir, "spd-say"), []byte("#!/bin/sh\n"), 0755); err != nil {
+		t.Fatalf("write spd-say: %v", err)
+	}
+	speakCommand = ""
+	if got := speechCommand(); len(got) == 0 || got[0] != "spd-say" {
+		t.Fatalf("expected spd-say to be picked, got %q", got)
+	}
+	if out := captureWarnings(t, checkSpeech); out != "" {
+		t.Fatalf("expected no warning with spd-say installed, got %q", out)
+	}
+}
+
+func TestCheckSounds(t *testing.T) {
+	defer resetGlobals()
+	dir := t.TempDir()
+	t.Setenv("PATH", dir)
+
+	if out := captureWarnings(t, checkSounds); out != "" {
+		t.Fatalf("expected no warning without sounds, got %q", out)
+	}
+	finishSound = filepath.Join(dir, "missing.wav")
+	out := captureWarnings(t, checkSounds)
+	if !strings.Contains(out, "finishSound") || !strings.Contains(out, "no sound player") {
+		t.Fatalf("expected missing file and player warnings, got %q", out)
+	}
+	playSound(finishSound) // Must not fail without a player
+}
+
+func BenchmarkFormatDuration(b *testing.B) {
+	b.ReportAllocs()
+	for i := 0; i < b.N; i++ {
+		formatDuration(time.Duration(i) * 37 * time.Millisecond)
+	}
+}
+
+func BenchmarkWriteSession(b *testing.B) {
+	dir := b.TempDir()
+	cwd, err := os.Getwd()
+	if err != nil {
+		b.Fatalf("Getwd: %v", err)
+	}
+	defer os.Chdir(cwd)
+	if err := os.Chdir(dir); err != nil {
+		b.Fatalf("Chdir: %v", err)
+	}
+	for i := 0; i < 500; i++ {
+		name := "session-" + strconv.Itoa(i)
+		writeSession(Session{Name: name, Mode: "timer", Elapsed: "10.0s", Remaining: "50.0s", Start: "2024-01-01:00-00-00"})
+	}
+	session := Session{Name: "session-250", Mode: "timer", Start: "2024-01-01:00-00-00"}
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		session.Elapsed = formatDuration(time.Duration(i) * time.Second)
+		writeSession(session)
+	}
+}
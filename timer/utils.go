@@ -0,0 +1,733 @@
+package timer
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"math"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"runtime"
+	"runtime/debug"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+	"unicode"
+)
+
+type TimerSummary struct {
+	Start    time.Time
+	End      time.Time
+	Duration time.Duration
+	Mode     string        // "timer" or "counter"
+	Finished bool          // true if completed, false if quit/interrupted
+	Name     string        // optional name for the timer
+	Total    time.Duration // countdown target; 0 for counters
+	Snoozed  bool          // the finish notification's snooze action was clicked
+}
+
+// remainingPercent returns the share of a countdown left (0-100, one decimal),
+// using the same rounding as the display; ok is false for counters
+func remainingPercent(summary TimerSummary) (float64, bool) {
+	if summary.Mode != "timer" || summary.Total <= 0 {
+		return 0, false
+	}
+	total := displaySeconds(summary.Total)
+	if total == 0 {
+		return 0, false
+	}
+	remaining := displaySeconds(summary.Total - summary.Duration)
+	return math.Round(float64(remaining)*1000/float64(total)) / 10, true
+}
+
+// summaryFormats are the accepted --format values for the final output
+var summaryFormats = []string{"human", "seconds", "clock", "json"}
+
+// formatSummary renders the summary printed after the TUI exits: the
+// multi-line human report, or a single line of seconds, hh:mm:ss or JSON
+func formatSummary(summary TimerSummary, format string) (string, error) {
+	switch format {
+	case "", "human":
+		var b strings.Builder
+		if summary.Name != "" {
+			fmt.Fprintf(&b, "Name: %s\n", summary.Name)
+		}
+		fmt.Fprintf(&b, "Start: %s\n", summary.Start.Format("2006-01-02 15:04:05"))
+		fmt.Fprintf(&b, "End: %s\n", summary.End.Format("2006-01-02 15:04:05"))
+		fmt.Fprintf(&b, "Duration: %s\n", summary.Duration)
+		fmt.Fprintf(&b, "Mode: %s\n", summary.Mode)
+		fmt.Fprintf(&b, "Finished: %t\n", summary.Finished)
+		return b.String(), nil
+	case "seconds":
+		return strconv.FormatFloat(summary.Duration.Round(time.Millisecond).Seconds(), 'f', -1, 64) + "\n", nil
+	case "clock":
+		total := int(summary.Duration.Round(time.Second).Seconds())
+		return fmt.Sprintf("%02d:%02d:%02d\n", total/3600, total%3600/60, total%60), nil
+	case "json":
+		var percent *float64
+		if p, ok := remainingPercent(summary); ok {
+			percent = &p
+		}
+		out, err := json.Marshal(struct {
+			Name     string   `json:"name,omitempty"`
+			Start    string   `json:"start"`
+			End      string   `json:"end"`
+			Duration float64  `json:"duration"` // Seconds
+			Mode     string   `json:"mode"`
+			Finished bool     `json:"finished"`
+			Percent  *float64 `json:"percent,omitempty"` // Remaining share of a countdown
+		}{
+			Name:     summary.Name,
+			Start:    summary.Start.Format(time.RFC3339),
+			End:      summary.End.Format(time.RFC3339),
+			Duration: summary.Duration.Round(time.Millisecond).Seconds(),
+			Mode:     summary.Mode,
+			Finished: summary.Finished,
+			Percent:  percent,
+		})
+		if err != nil {
+			return "", err
+		}
+		return string(out) + "\n", nil
+	}
+	return "", fmt.Errorf("unknown format %q (want %s)", format, strings.Join(summaryFormats, ", "))
+}
+
+type Session struct {
+	Start      string `json:"start"`
+	Current    string `json:"current"`
+	Elapsed    string `json:"elapsed"`
+	Remaining  string `json:"remaining,omitempty"` // Only for timer mode
+	Overtime   string `json:"overtime,omitempty"`  // How far a countdown has run past zero
+	Paused     bool   `json:"paused"`
+	Mode       string `json:"mode"` // "timer" or "counter"
+	Name       string `json:"name,omitempty"`
+	Finished   bool   `json:"finished"`
+	Inline     bool   `json:"inline"`               // true if inline mode, false if fullscreen
+	Goal       string `json:"goal,omitempty"`       // Only for counter mode with a goal
+	ShowToGoal bool   `json:"showToGoal,omitempty"` // Counter shows time left to its goal instead of elapsed
+	Night      bool   `json:"night,omitempty"`      // Drawn dim, toggled with 'n'
+	Adjust     string `json:"adjust,omitempty"`     // Pending "+5m"/"-2m" change from the CLI
+	Reset      bool   `json:"reset,omitempty"`      // Set by `timer reset` for a running counter to pick up
+
+	Description string `json:"description,omitempty"` // What the timer is for, shown below the digits
+	Group       string `json:"group,omitempty"`       // Shared by sessions whose time `timer watch` adds up
+
+	Pauses  []PauseInterval `json:"pauses,omitempty"`  // Every pause so far, oldest first
+	PauseAt []string        `json:"pauseAt,omitempty"` // -pause-at points that haven't fired yet
+
+	Overrides *SessionOverrides `json:"overrides,omitempty"` // Optional per-session config
+}
+
+// PauseInterval records one pause of a session and the optional reason given with 'p'
+type PauseInterval struct {
+	Start  string `json:"start"`
+	End    string `json:"end,omitempty"` // Empty while the pause lasts
+	Reason string `json:"reason,omitempty"`
+}
+
+// openPause starts a new pause interval at the given time
+func openPause(pauses []PauseInterval, at time.Time) []PauseInterval {
+	return append(pauses, PauseInterval{Start: at.Format(sessionTimeLayout)})
+}
+
+// closePause ends the ongoing pause interval, if any, at the given time
+func closePause(pauses []PauseInterval, at time.Time) {
+	if n := len(pauses); n > 0 && pauses[n-1].End == "" {
+		pauses[n-1].End = at.Format(sessionTimeLayout)
+	}
+}
+
+// pauseReason returns the reason given for the ongoing pause, if any
+func pauseReason(pauses []PauseInterval) string {
+	if n := len(pauses); n > 0 && pauses[n-1].End == "" {
+		return pauses[n-1].Reason
+	}
+	return ""
+}
+
+// SessionOverrides holds per-session settings that take precedence over config.json
+type SessionOverrides struct {
+	WarningThreshold time.Duration `json:"warningThreshold,omitempty"`
+	InlineWidth      int           `json:"inlineWidth,omitempty"`
+	GlyphStyle       string        `json:"glyphStyle,omitempty"`
+}
+
+// hasWarningThreshold reports whether the session sets a warning threshold
+// within the 1m-1h range the global warningThreshold accepts
+func (o *SessionOverrides) hasWarningThreshold() bool {
+	return o != nil && o.WarningThreshold >= 1*time.Minute && o.WarningThreshold <= 1*time.Hour
+}
+
+// warningThreshold returns the session's warning threshold, falling back to the global one
+func (o *SessionOverrides) warningThreshold() time.Duration {
+	if !o.hasWarningThreshold() {
+		return warningThreshold
+	}
+	return o.WarningThreshold
+}
+
+// inlineWidth returns the columns the inline display may use, from the session
+// or the global config; 0 means the terminal width
+func (o *SessionOverrides) inlineWidth() int {
+	if o == nil || o.InlineWidth <= 0 {
+		return inlineWidth
+	}
+	return o.InlineWidth
+}
+
+// thresholdFor returns the warning threshold for a countdown of the given total
+// duration, applying a percentage warningThreshold unless the session overrides it
+func (o *SessionOverrides) thresholdFor(total time.Duration) time.Duration {
+	if !o.hasWarningThreshold() && warningPercent > 0 && total > 0 {
+		return time.Duration(float64(total) * warningPercent / 100)
+	}
+	return o.warningThreshold()
+}
+
+// warnOut receives non-fatal warnings; tests replace it to capture them
+var warnOut io.Writer = os.Stderr
+
+// warnf prints a non-fatal warning
+func warnf(format string, args ...any) {
+	fmt.Fprintf(warnOut, "Warning: "+format+"\n", args...)
+}
+
+// versionString describes the running build, falling back to the module and VCS
+// info recorded by the Go toolchain when no -ldflags values were stamped
+func versionString() string {
+	v, c, d := version, commit, buildDate
+	if info, ok := debug.ReadBuildInfo(); ok {
+		if v == "dev" && info.Main.Version != "" && info.Main.Version != "(devel)" {
+			v = info.Main.Version
+		}
+		for _, setting := range info.Settings {
+			switch {
+			case setting.Key == "vcs.revision" && c == "none":
+				c = setting.Value
+				if len(c) > 7 {
+					c = c[:7]
+				}
+			case setting.Key == "vcs.time" && d == "unknown":
+				d = setting.Value
+			}
+		}
+	}
+	return fmt.Sprintf("timer version %s (commit %s, built %s)", v, c, d)
+}
+
+// durationUnits maps accepted unit spellings (lowercased) to Go's duration units
+var durationUnits = map[string]string{
+	"ns": "ns", "us": "us", "µs": "µs", "ms": "ms",
+	"s": "s", "sec": "s", "secs": "s",
+	"m": "m", "min": "m", "mins": "m",
+	"h": "h", "hr": "h", "hrs": "h",
+}
+
+// normalizeDurationArg rewrites casual input such as "5 S", "2.5  Sec" or
+// "1 hr 30 min" to Go duration syntax, reading a lone number as seconds.
+// ok is false unless the input is an optional sign followed by number+unit
+// pairs, so "5 3" or "1h 30" stay invalid rather than being guessed at.
+func normalizeDurationArg(arg string) (string, bool) {
+	s := strings.ToLower(strings.TrimSpace(arg))
+	var b strings.Builder
+	if s != "" && (s[0] == '+' || s[0] == '-') {
+		b.WriteByte(s[0])
+		s = s[1:]
+	}
+	pairs := 0
+	for s != "" {
+		n := strings.IndexFunc(s, func(r rune) bool { return (r < '0' || r > '9') && r != '.' })
+		if n < 0 {
+			n = len(s)
+		}
+		if n == 0 {
+			return "", false
+		}
+		number := s[:n]
+		s = strings.TrimLeft(s[n:], " \t")
+		u := strings.IndexFunc(s, func(r rune) bool { return !unicode.IsLetter(r) })
+		if u < 0 {
+			u = len(s)
+		}
+		unit := s[:u]
+		s = strings.TrimLeft(s[u:], " \t")
+		if unit == "" {
+			// A bare number is only allowed on its own
+			if pairs > 0 || s != "" {
+				return "", false
+			}
+			unit = "s"
+		}
+		goUnit, ok := durationUnits[unit]
+		if !ok {
+			return "", false
+		}
+		b.WriteString(number + goUnit)
+		pairs++
+	}
+	return b.String(), pairs > 0
+}
+
+// parseDurationArg parses a duration argument ("90", "2.5", "5m", "1h30m",
+// "5 S", "2 mins"), reading plain numbers as seconds. The result must be
+// strictly positive.
+func parseDurationArg(arg string) (time.Duration, error) {
+	s, ok := normalizeDurationArg(arg)
+	if !ok {
+		return 0, fmt.Errorf("invalid duration %q", arg)
+	}
+	d, err := time.ParseDuration(s)
+	if err != nil {
+		return 0, fmt.Errorf("invalid duration %q", arg)
+	}
+	if d <= 0 {
+		return 0, fmt.Errorf("duration %q must be positive", arg)
+	}
+	return d, nil
+}
+
+// parseRelativeArg parses a signed adjustment such as "+5m" or "-90"
+func parseRelativeArg(arg string) (time.Duration, error) {
+	if len(arg) < 2 || (arg[0] != '+' && arg[0] != '-') || arg[1] == '+' || arg[1] == '-' {
+		return 0, fmt.Errorf("invalid adjustment %q: want +<duration> or -<duration>", arg)
+	}
+	d, err := parseDurationArg(arg[1:])
+	if err != nil {
+		return 0, fmt.Errorf("invalid adjustment %q", arg)
+	}
+	if arg[0] == '-' {
+		d = -d
+	}
+	return d, nil
+}
+
+// formatParsed prints a duration parsed by `timer parse`: its seconds and its
+// formatDuration form separated by a tab, or a JSON object for -format json
+func formatParsed(d time.Duration, format string) string {
+	seconds := strconv.FormatFloat(d.Seconds(), 'f', -1, 64)
+	if format == "json" {
+		return fmt.Sprintf(`{"seconds":%s,"duration":%q}`, seconds, formatDuration(d))
+	}
+	return seconds + "\t" + formatDuration(d)
+}
+
+// isNegativeDuration reports whether a command-line argument is a negative
+// duration ("-5s", "-10") rather than a flag
+func isNegativeDuration(arg string) bool {
+	if len(arg) < 2 || arg[0] != '-' || arg[1] < '0' || arg[1] > '9' {
+		return false
+	}
+	s, ok := normalizeDurationArg(arg)
+	if !ok {
+		return false
+	}
+	_, err := time.ParseDuration(s)
+	return err == nil
+}
+
+// formatDuration formats a duration for sessions.json and summaries:
+//   - exactly zero is "0s"
+//   - anything else is seconds with one decimal place, rounded half away
+//     from zero to the nearest 0.1s ("0.3s" for 250ms, "10.0s", "0.0s" for 20ms)
+//
+// parseFormattedDuration reads all of these back.
+func formatDuration(d time.Duration) string {
+	if d == 0 {
+		return "0s"
+	}
+	// Whole tenths of a second, written by hand rather than with fmt: this runs
+	// on every session write, so it builds the string in a single allocation
+	tenths := int64(d.Round(100*time.Millisecond) / (100 * time.Millisecond))
+	var buf [24]byte
+	b := buf[:0]
+	if tenths < 0 {
+		b = append(b, '-')
+		tenths = -tenths
+	}
+	b = strconv.AppendInt(b, tenths/10, 10)
+	b = append(b, '.', byte('0'+tenths%10), 's')
+	return string(b)
+}
+
+// storedDuration formats a duration for sessions.json, rounding to whole
+// seconds when roundElapsed is set so repeated writes stay stable
+func storedDuration(d time.Duration) string {
+	if roundElapsed {
+		d = d.Round(time.Second)
+	}
+	return formatDuration(d)
+}
+
+// parseFormattedDuration reads a duration written by formatDuration ("0s",
+// "0.0s", "1.5s", "0.25s"); hand-edited Go durations such as "1m30s" are
+// accepted too. Anything else is treated as zero.
+func parseFormattedDuration(s string) time.Duration {
+	if sec, err := strconv.ParseFloat(strings.TrimSuffix(s, "s"), 64); err == nil && strings.HasSuffix(s, "s") {
+		return time.Duration(math.Round(sec * float64(time.Second)))
+	}
+	if d, err := time.ParseDuration(s); err == nil {
+		return d
+	}
+	return 0
+}
+
+// normalizeSessionName trims whitespace and validates that a session name only
+// contains letters, digits, '_' and '-'. An empty name selects the default session.
+func normalizeSessionName(name string) (string, error) {
+	name = strings.TrimSpace(name)
+	for _, r := range name {
+		if !(r >= 'a' && r <= 'z' || r >= 'A' && r <= 'Z' || r >= '0' && r <= '9' || r == '_' || r == '-') {
+			return "", fmt.Errorf("invalid session name %q: only letters, digits, '_' and '-' are allowed", name)
+		}
+	}
+	return name, nil
+}
+
+// readSessions decodes sessions.json and reconciles hand-edited entries: the
+// map key wins over a mismatched Name, and of duplicate keys the last one is
+// kept. It returns a description of every fix made.
+func readSessions(data []byte) (map[string]Session, []string, error) {
+	var sessions map[string]Session
+	if err := json.Unmarshal(data, &sessions); err != nil {
+		return nil, nil, err
+	}
+	var fixes []string
+	for _, key := range duplicateKeys(data) {
+		fixes = append(fixes, fmt.Sprintf("duplicate session %q; keeping the last entry", key))
+	}
+	for key, session := range sessions {
+		if session.Name == key || key == "default" && session.Name == "" {
+			continue
+		}
+		fixes = append(fixes, fmt.Sprintf("session %q has name %q; using %q", key, session.Name, key))
+		session.Name = key
+		if key == "default" {
+			session.Name = ""
+		}
+		sessions[key] = session
+	}
+	sort.Strings(fixes)
+	return sessions, fixes, nil
+}
+
+// duplicateKeys returns the top-level keys that appear more than once in a JSON object
+func duplicateKeys(data []byte) []string {
+	dec := json.NewDecoder(bytes.NewReader(data))
+	if tok, err := dec.Token(); err != nil || tok != json.Delim('{') {
+		return nil
+	}
+	seen := make(map[string]bool)
+	var dups []string
+	for dec.More() {
+		tok, err := dec.Token()
+		if err != nil {
+			return dups
+		}
+		key, _ := tok.(string)
+		if seen[key] {
+			dups = append(dups, key)
+		}
+		seen[key] = true
+		var skip json.RawMessage
+		if err := dec.Decode(&skip); err != nil {
+			return dups
+		}
+	}
+	return dups
+}
+
+// adjustSession queues a "+5m"/"-2m" change on a stored, unfinished session.
+// A running timer applies it on its next tick; otherwise it is applied when
+// the session is restored.
+func adjustSession(name string, delta time.Duration) (Session, error) {
+	return updateSession(name, func(session *Session) error {
+		if session.Finished {
+			return badInputf("session %q has already finished", name)
+		}
+		pending := parseFormattedDuration(session.Adjust) + delta
+		session.Adjust = ""
+		if pending != 0 {
+			session.Adjust = formatDuration(pending)
+		}
+		return nil
+	})
+}
+
+// restoredElapsed is the elapsed time a restored session resumes from. With
+// restoreDowntime "freeze" that is the stored elapsed; with "continue" a session
+// that was running when last written also counts the time since then, as if
+// it had kept going while no process was running it.
+func restoredElapsed(session Session, at time.Time) time.Duration {
+	elapsed := parseFormattedDuration(session.Elapsed)
+	if restoreDowntime != "continue" || session.Paused || session.Finished {
+		return elapsed
+	}
+	current, err := time.ParseInLocation(sessionTimeLayout, session.Current, time.Local)
+	if err != nil || current.After(at) {
+		return elapsed
+	}
+	return elapsed + at.Sub(current)
+}
+
+// sessionTimeLayout formats the start/current timestamps in sessions.json
+const sessionTimeLayout = "2006-01-02:15-04-05"
+
+// todayTotal sums the elapsed time of sessions that finished on day's date,
+// skipping the session keyed exclude (the one about to be overwritten)
+func todayTotal(sessions map[string]Session, exclude string, day time.Time) time.Duration {
+	date := day.Format("2006-01-02")
+	var total time.Duration
+	for key, session := range sessions {
+		if key == exclude || !session.Finished || !strings.HasPrefix(session.Current, date) {
+			continue
+		}
+		total += parseFormattedDuration(session.Elapsed)
+	}
+	return total
+}
+
+// loadTodayTotal reads sessions.json for todayTotal; a missing or broken
+// store simply counts as nothing done yet
+func loadTodayTotal(name string) time.Duration {
+	if name == "" {
+		name = "default"
+	}
+	data, err := os.ReadFile("sessions.json")
+	if err != nil {
+		return 0
+	}
+	sessions, _, err := readSessions(data)
+	if err != nil {
+		return 0
+	}
+	return todayTotal(sessions, name, now())
+}
+
+// resetSession zeroes a stored counter session's elapsed time, keeping its
+// name, goal and overrides. A running counter restarts from zero on its next tick.
+func resetSession(name string) (Session, error) {
+	return updateSession(name, func(session *Session) error {
+		if session.Mode != "counter" {
+			return badInputf("session %q is not a counter", name)
+		}
+		current := now().Format(sessionTimeLayout)
+		session.Start, session.Current = current, current
+		session.Elapsed = formatDuration(0)
+		session.Adjust = ""
+		session.Pauses = nil
+		session.Finished = false
+		session.Reset = true
+		return nil
+	})
+}
+
+// writeFileAtomic replaces path with data via a temporary file and rename, so
+// readers never see a half-written file even if the write is interrupted
+func writeFileAtomic(path string, data []byte) error {
+	tmp, err := os.CreateTemp(filepath.Dir(path), filepath.Base(path)+".tmp*")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(tmp.Name()) // No-op after a successful rename
+	if _, err := tmp.Write(data); err != nil {
+		tmp.Close()
+		return err
+	}
+	if err := tmp.Chmod(0644); err != nil {
+		tmp.Close()
+		return err
+	}
+	if err := tmp.Close(); err != nil {
+		return err
+	}
+	return os.Rename(tmp.Name(), path)
+}
+
+// notifyCommand sends the desktop notification when a countdown finishes (Linux)
+const notifyCommand = "notify-send"
+
+// humanizeDuration spells a duration out in words to the second, such as
+// "25 minutes" or "1 hour, 2 minutes and 1 second", leaving out zero parts
+func humanizeDuration(d time.Duration) string {
+	total := int64(d.Round(time.Second) / time.Second)
+	if total <= 0 {
+		return "0 seconds"
+	}
+	units := []struct {
+		name string
+		size int64
+	}{{"hour", 3600}, {"minute", 60}, {"second", 1}}
+	var parts []string
+	for _, u := range units {
+		n := total / u.size
+		total %= u.size
+		if n == 0 {
+			continue
+		}
+		part := fmt.Sprintf("%d %s", n, u.name)
+		if n != 1 {
+			part += "s"
+		}
+		parts = append(parts, part)
+	}
+	if len(parts) == 1 {
+		return parts[0]
+	}
+	return strings.Join(parts[:len(parts)-1], ", ") + " and " + parts[len(parts)-1]
+}
+
+// notify sends a desktop notification on Linux, titled with the timer name
+func notify(name, message string) {
+	if runtime.GOOS != "linux" {
+		return
+	}
+	title := "Timer"
+	if name != "" {
+		title = name
+	}
+	exec.Command(notifyCommand, title, message).Run()
+}
+
+// snoozeWait caps how long a finished timer waits on the notification for a
+// snooze click before exiting
+var snoozeWait = time.Minute
+
+// notifySnooze sends the finish notification with a "Snooze" action and
+// reports whether it was clicked. A notifier without action support gets a
+// plain notification instead; no answer within snoozeWait, or ctx being
+// cancelled first, means no.
+func notifySnooze(ctx context.Context, name, message string, snooze time.Duration) bool {
+	if runtime.GOOS != "linux" {
+		return false
+	}
+	title := "Timer"
+	if name != "" {
+		title = name
+	}
+	ctx, cancel := context.WithTimeout(ctx, snoozeWait)
+	defer cancel()
+	out, err := exec.CommandContext(ctx, notifyCommand, "--wait", "--action=snooze=Snooze "+humanizeDuration(snooze), title, message).Output()
+	if err != nil {
+		if ctx.Err() == nil {
+			notify(name, message)
+		}
+		return false
+	}
+	return strings.TrimSpace(string(out)) == "snooze"
+}
+
+// parseCheckpoints parses checkpoint durations ("10m", "90") and merges them
+// with existing ones, sorted descending without duplicates
+func parseCheckpoints(existing []time.Duration, list []string) ([]time.Duration, error) {
+	merged := append([]time.Duration(nil), existing...)
+	for _, item := range list {
+		item = strings.TrimSpace(item)
+		if item == "" {
+			continue
+		}
+		d, err := parseDurationArg(item)
+		if err != nil {
+			return existing, fmt.Errorf("invalid checkpoint: %w", err)
+		}
+		merged = append(merged, d)
+	}
+	sort.Slice(merged, func(i, j int) bool { return merged[i] > merged[j] })
+	out := merged[:0]
+	for i, d := range merged {
+		if i == 0 || d != merged[i-1] {
+			out = append(out, d)
+		}
+	}
+	return out, nil
+}
+
+// crossedPausePoint returns the index of the -pause-at point the timer has
+// reached first among those not yet fired, or -1. Points are remaining time for
+// a countdown of the given duration and elapsed time for a stopwatch.
+func crossedPausePoint(points []time.Duration, fired []bool, elapsed, duration time.Duration) int {
+	best := -1
+	var bestAt time.Duration
+	for i, p := range points {
+		at := p
+		if duration > 0 {
+			at = duration - p
+		}
+		if !fired[i] && elapsed >= at && (best < 0 || at < bestAt) {
+			best, bestAt = i, at
+		}
+	}
+	return best
+}
+
+// pendingPausePoints lists the -pause-at points still to fire, for sessions.json
+func pendingPausePoints(points []time.Duration, fired []bool) []string {
+	var pending []string
+	for i, p := range points {
+		if !fired[i] {
+			pending = append(pending, formatDuration(p))
+		}
+	}
+	return pending
+}
+
+// checkNotifier warns once at startup when finish notifications can't be
+// delivered because notify-send is missing, rather than failing silently later
+func checkNotifier() {
+	if !notifyCheck || runtime.GOOS != "linux" {
+		return
+	}
+	if _, err := exec.LookPath(notifyCommand); err != nil {
+		warnf("%s not found; finish notifications are disabled (set \"notifyCheck\": false to hide this)", notifyCommand)
+	}
+}
+
+// loadSession reads a stored session, saving back any corrections readSessions
+// makes to hand-edited entries
+func loadSession(name string) (Session, error) {
+	path, err := filepath.Abs("sessions.json")
+	if err != nil {
+		return Session{}, err
+	}
+	defer lockSessions(path)()
+	return loadSessionLocked(path, name)
+}
+
+// loadSessionLocked is loadSession for a caller already holding lockSessions
+func loadSessionLocked(path, name string) (Session, error) {
+	name, err := normalizeSessionName(name)
+	if err != nil {
+		return Session{}, err
+	}
+	if name == "" {
+		name = "default"
+	}
+	data, err := os.ReadFile(path)
+	if errors.Is(err, fs.ErrNotExist) {
+		return Session{}, sessionNotFoundError{name}
+	}
+	if err != nil {
+		return Session{}, fmt.Errorf("failed to read sessions.json: %w", err)
+	}
+	sessions, fixes, err := readSessions(data)
+	if err != nil {
+		return Session{}, fmt.Errorf("failed to parse sessions.json: %w", err)
+	}
+	for _, fix := range fixes {
+		warnf("sessions.json: %s", fix)
+	}
+	if len(fixes) > 0 {
+		rewriteSessions(path, sessions)
+	}
+	session, ok := sessions[name]
+	if !ok {
+		return Session{}, sessionNotFoundError{name}
+	}
+	return session, nil
+}
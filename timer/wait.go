@@ -0,0 +1,77 @@
+package timer
+
+import (
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"time"
+)
+
+// sleep pauses between polls; tests replace it along with now
+var sleep = time.Sleep
+
+// waitPollInterval picks how often to check a session, like getTickerInterval:
+// a countdown close to its end is polled fast, anything else slowly
+func waitPollInterval(session Session, found bool) time.Duration {
+	if !found || session.Mode != "timer" || session.Paused || session.Finished {
+		return tickIntervalSlow
+	}
+	remaining := parseFormattedDuration(session.Remaining)
+	if remaining <= 0 {
+		return tickIntervalFast
+	}
+	return getTickerInterval(remaining)
+}
+
+// waitForSession blocks until the named session in sessions.json is finished.
+// A run that had already finished when waiting began doesn't count: that waits
+// for the next run, told apart by its start time. It fails when the session
+// disappears after being seen, or when timeout (0 = none) passes before it
+// finishes or, if it never shows up, appears.
+func waitForSession(name string, timeout time.Duration) error {
+	if name == "" {
+		name = "default"
+	}
+	deadline := now().Add(timeout)
+	seen := false
+	// Start of a run found already finished, when there was one
+	staleStart, stale := "", false
+	for first := true; ; first = false {
+		var session Session
+		found := false
+		data, err := os.ReadFile("sessions.json")
+		if err != nil && !errors.Is(err, fs.ErrNotExist) {
+			return fmt.Errorf("failed to read sessions.json: %w", err)
+		}
+		if err == nil {
+			// A store caught mid-edit by hand is simply retried on the next poll
+			if sessions, _, err := readSessions(data); err == nil {
+				session, found = sessions[name]
+			}
+		}
+		if first && found && session.Finished {
+			staleStart, stale = session.Start, true
+		}
+		switch {
+		case found && session.Finished && !(stale && session.Start == staleStart):
+			return nil
+		case found:
+			seen = true
+		case seen:
+			return fmt.Errorf("session %q was deleted before it finished", name)
+		}
+
+		if timeout > 0 && !now().Before(deadline) {
+			if !seen {
+				return fmt.Errorf("session %q did not appear within %v", name, timeout)
+			}
+			return fmt.Errorf("session %q did not finish within %v", name, timeout)
+		}
+		interval := waitPollInterval(session, found)
+		if timeout > 0 {
+			interval = min(interval, deadline.Sub(now()))
+		}
+		sleep(interval)
+	}
+}
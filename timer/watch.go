@@ -0,0 +1,106 @@
+package timer
+
+import (
+	"fmt"
+	"os"
+	"os/signal"
+	"sort"
+	"strings"
+	"syscall"
+	"time"
+	"unicode/utf8"
+)
+
+// groupElapsed is the time a stored session has run by the given time,
+// counting on from its last write while it is running elsewhere
+func groupElapsed(session Session, at time.Time) time.Duration {
+	return parseFormattedDuration(session.Elapsed) + liveExtra(session, at)
+}
+
+// watchLines lists the members of a group, one "name  elapsed" line each in
+// name order padded to a common width so they stay aligned when centered, and
+// returns the group's combined elapsed time
+func watchLines(sessions map[string]Session, group string, at time.Time) ([]string, time.Duration) {
+	var members []Session
+	nameWidth := 0
+	for _, session := range sessions {
+		if session.Group != group {
+			continue
+		}
+		members = append(members, session)
+		nameWidth = max(nameWidth, utf8.RuneCountInString(session.Name))
+	}
+	sort.Slice(members, func(i, j int) bool { return members[i].Name < members[j].Name })
+
+	lines := make([]string, 0, len(members))
+	var total time.Duration
+	lineWidth := 0
+	for _, session := range members {
+		elapsed := groupElapsed(session, at)
+		total += elapsed
+		line := session.Name + strings.Repeat(" ", nameWidth-utf8.RuneCountInString(session.Name)) + "  " + formatHMS(elapsed)
+		if session.Finished {
+			line += " (finished)"
+		} else if session.Paused {
+			line += " (paused)"
+		}
+		lines = append(lines, line)
+		lineWidth = max(lineWidth, utf8.RuneCountInString(line))
+	}
+	for i, line := range lines {
+		lines[i] = line + strings.Repeat(" ", lineWidth-utf8.RuneCountInString(line))
+	}
+	return lines, total
+}
+
+// runWatch implements `timer watch -group NAME`: a read-only view of the
+// group's combined time in big digits with each member listed below it,
+// following sessions.json until q
+func runWatch(group string) error {
+	restore, err := enterRawMode()
+	if err != nil {
+		return err
+	}
+	defer restore()
+	fmt.Fprint(tuiOut, altScreen+hideCursor)
+	defer fmt.Fprint(tuiOut, showCursor+mainScreen)
+
+	sigCh := make(chan os.Signal, 1)
+	signal.Notify(sigCh, os.Interrupt, syscall.SIGTERM, syscall.SIGWINCH)
+	defer signal.Stop(sigCh)
+
+	quitCh := viewQuitKeys()
+
+	draw := func() {
+		width, height := getTerminalSize()
+		var sessions map[string]Session
+		if data, err := os.ReadFile("sessions.json"); err == nil {
+			sessions, _, _ = readSessions(data)
+		}
+		lines, total := watchLines(sessions, group, now())
+		list := strings.Join(lines, "\n")
+		if len(lines) == 0 {
+			list = "(no sessions)"
+		}
+		captionLines := 4 + strings.Count(list, "\n")
+		bigText := withCaptions(renderBigTime(formatHMS(total), width, height-captionLines), group+" total", list)
+		fmt.Fprint(tuiOut, clearScreen+fixNewlines(centerText(bigText, width, height)))
+	}
+
+	ticker := time.NewTicker(tickIntervalMedium)
+	defer ticker.Stop()
+	draw()
+	for {
+		select {
+		case <-ticker.C:
+			draw()
+		case sig := <-sigCh:
+			if sig != syscall.SIGWINCH {
+				return nil
+			}
+			draw() // Reflow to the new size
+		case <-quitCh:
+			return nil
+		}
+	}
+}